@@ -25,19 +25,20 @@ This module: is the main logic of json parsing
 
 
 TODOS:
- - stepB, validation: {} [] "" pairings, missing commas, colons?
  - error handling, use errorsCollected everywhere
- - special number handling (e, hexa)
  - dedicated tests for all files against errors, for all functions
 */
 
 package jyp
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"sort"
 	"strconv"
+	"unicode/utf16"
+	"unicode/utf8"
 )
 
 var errorPrefix = "Error: "
@@ -63,7 +64,9 @@ type tokenElem struct {
 
                       n null               110
                       ? not identified,     63
-	                    only saved: later the type can be defined
+	                    only saved: later the type can be defined.
+	                    After stepA, a '?' token is an invalid literal (tru, nul, abc for example)
+                      U unclosed string     85 the src ended inside a string
 	*/
 	posInSrcFirst int
 	posInSrcLast  int
@@ -91,14 +94,20 @@ type JSON_value struct {
 	ValType rune
 
 	// ...... these values represent a Json elem's value - and one of them is filled only.. ..........
-	ValObject map[string]JSON_value
-	ValArray  []JSON_value
+	ValObject      map[string]JSON_value
+	ValObjectOrder map[string]int // the insertion order of the object keys
+	ValArray       []JSON_value
 
 	ValBool bool // true, false
 
 	ValRunes       string  // the parsed string. \n means 1 char here, for example
 	ValNumberInt   int     // an integer JSON value is stored here
 	ValNumberFloat float64 // a float JSON value is saved here
+	ValNumberRaw   string  // the number literal of the src, with Options.NumbersRaw, or if an integer is too big for int
+
+	Comments []JSON_comment // JSONC trivia, filled only if Options.Comments is used
+	Errors   []error        // the problems of a broken node, filled only if Options.Recover is used
+	Pos      *SrcSpans      // the positions in the src, filled only if Options.Positions is used
 }
 
 func (v JSON_value) ValObject_keys_sorted() []string{
@@ -110,21 +119,72 @@ func (v JSON_value) ValObject_keys_sorted() []string{
 	return keys
 }
 
+// the keys in insertion order. A key that was set directly in ValObject
+// (without AddKeyVal) has no known order, these keys are at the end, sorted
+func (v JSON_value) ValObject_keys_ordered() []string { // TESTED
+	keys := v.ValObject_keys_sorted()
+	sort.SliceStable(keys, func(a, b int) bool {
+		orderA, isKnownA := v.ValObjectOrder[keys[a]]
+		orderB, isKnownB := v.ValObjectOrder[keys[b]]
+		if isKnownA && isKnownB {
+			return orderA < orderB
+		}
+		return isKnownA && !isKnownB
+	})
+	return keys
+}
+
+func (v JSON_value) valObject_keys(insertionOrder bool) []string {
+	if insertionOrder {
+		return v.ValObject_keys_ordered()
+	}
+	return v.ValObject_keys_sorted()
+}
+
+// set the key in the object, a new key is registered in the insertion order.
+// The maps are shared with the copies of v, so a value receiver is enough
+func (v JSON_value) valObject_set(key string, value JSON_value) {
+	if _, isKeyUsed := v.ValObject[key]; !isKeyUsed && v.ValObjectOrder != nil {
+		if _, isOrdered := v.ValObjectOrder[key]; !isOrdered {
+			v.ValObjectOrder[key] = len(v.ValObjectOrder)
+		}
+	}
+	v.ValObject[key] = value
+}
+
+
 
+func stepA__tokensTableDetect_structuralTokens_strings_L1(src []byte) tokenElems { // TESTED
+	// the src is processed as bytes: every structural char is ascii, and in utf8
+	// the bytes of a multi-byte char are never ascii, so they cannot be mixed with them
+	tokenTable := make(tokenElems, 0, len(src)/8) // estimated token num, to avoid the frequent re-allocations
+	tokenTable, posStringStart := stepA__tokensTableDetect_section_L2(src, 0, len(src), -1, tokenTable)
 
-func stepA__tokensTableDetect_structuralTokens_strings_L1(srcRunes []rune) tokenElems { // TESTED
-	tokenTable := tokenElems{}
+	// the src can end inside a non-closed string, it is not closed by a quote
+	if posStringStart != -1 {
+		tokenTable = append(tokenTable, tokenElem{tokenType: 'U', posInSrcFirst: posStringStart, posInSrcLast: len(src)-1})
+	}
+	return tokenTable
+}
+
+/* tokenize the src[posFrom:posTo] section, the token positions are src positions.
+   posStringStart: -1 if the section starts outside of strings,
+                   stringStartedBeforeSection if the section starts inside a string (used by the parallel tokenizer)
+   the returned posStringStart is the state at the end of the section (-1: not in string).
+   An unknown block (number, true...) is always closed at the end of the section. */
+func stepA__tokensTableDetect_section_L2(src []byte, posFrom, posTo int, posStringStart int, tokenTable tokenElems) (tokenElems, int) { // TESTED
 	posUnknownBlockStart := -1 // used only if the token is longer than 1 char. numbers, false/true for example
 	
 	//////////// TOKEN ADD //////////////////////////
 	tokenAdd := func (typeOfToken rune, posFirst, posLast int) {
 		if typeOfToken == '?' {
-			textInSrc := string(srcRunes[posFirst:posLast+1])
-			if textInSrc == "true" { typeOfToken = 't' } else
-			if textInSrc == "false" { typeOfToken = 'f' } else
-			if textInSrc == "null" { typeOfToken = 'n' } else {
+			textInSrc := src[posFirst:posLast+1]
+			if string(textInSrc) == "true" { typeOfToken = 't' } else
+			if string(textInSrc) == "false" { typeOfToken = 'f' } else
+			if string(textInSrc) == "null" { typeOfToken = 'n' } else
+			if base__is_number_start_rune(rune(src[posFirst])) {
 				typeOfToken = '0' // if eveything else is processed, only number can be the last choice
-			}
+			} // else: the token stays '?', an invalid literal. stepB reports it
 		}
 
 		tokenTable = append(tokenTable, tokenElem{tokenType: typeOfToken, posInSrcFirst: posFirst, posInSrcLast: posLast}  )
@@ -132,24 +192,28 @@ func stepA__tokensTableDetect_structuralTokens_strings_L1(srcRunes []rune) token
 
 	inUnknownBlock := func () bool { return posUnknownBlockStart != -1	}
 	
-	posStringStart := -1  //////////////////////////////////////////
 	inString := func () bool { // if string start position detected,
 		return posStringStart != -1    // we are in String detection
 	} //////////////////////////////////////////////////////////////
 	/*
-	inStringDebug := func(runeNow rune) string {
+	inStringDebug := func(byteNow byte) string {
 		info := " "
-		if inString() || runeNow == '"' { info = "S" } // display S for debugging, when inString==true
+		if inString() || byteNow == '"' { info = "S" } // display S for debugging, when inString==true
 		return info
 	}
 	 */
 	isEscaped := false
 
-	for pos, runeNow := range srcRunes {
+	for posInSection, byteNow := range src[posFrom:posTo] {
+		pos := posFrom + posInSection
 
 		stringCloseAtEnd := false
-		if runeNow == '"' {
+		if byteNow == '"' {
 			if ! inString() {
+				if inUnknownBlock() { // a literal cannot contain a quote: abc"text" is two tokens
+					tokenAdd('?', posUnknownBlockStart, pos-1)
+					posUnknownBlockStart = -1
+				}
 				posStringStart = pos // posStringStart is modified only if interval is started
 			} else { // in string processing:
 				if ! isEscaped { // and not escaped:
@@ -160,7 +224,7 @@ func stepA__tokensTableDetect_structuralTokens_strings_L1(srcRunes []rune) token
 
 		// detect tokens:
 		if ! inString() { // json structural chars:
-			if base__is_whitespace_rune(runeNow) {
+			if base__is_whitespace_byte(byteNow) {
 				if ! inUnknownBlock() {
 					// skip the whitespaces from tokens if the pos is NOT in unknown block,
 					// so don't start an unknown block with a whitespace
@@ -171,12 +235,12 @@ func stepA__tokensTableDetect_structuralTokens_strings_L1(srcRunes []rune) token
 					tokenAdd('?', posUnknownBlockStart, pos-1)
 					posUnknownBlockStart = -1
 				}
-			} else if runeNow == '{' || runeNow == '}' || runeNow == '[' || runeNow == ']' || runeNow == ',' || runeNow == ':' {
+			} else if byteNow == '{' || byteNow == '}' || byteNow == '[' || byteNow == ']' || byteNow == ',' || byteNow == ':' {
 				if inUnknownBlock() {
 					tokenAdd('?', posUnknownBlockStart, pos-1)
 					posUnknownBlockStart = -1
 				}
-				tokenAdd(runeNow, pos, pos)
+				tokenAdd(rune(byteNow), pos, pos)
 			} else {
 				// not in string, and not json structural char and not whitespace
 				// so it can be a number, true/false/null or
@@ -193,70 +257,354 @@ func stepA__tokensTableDetect_structuralTokens_strings_L1(srcRunes []rune) token
 				tokenAdd('"', posStringStart, pos)
 				posStringStart = -1
 			} else { // not string closing
-				if runeNow == '\\' {
+				if byteNow == '\\' {
 					isEscaped = ! isEscaped
 				} else { // the escape series ended :-)
 					isEscaped = false
 				}
 			}
 		}
-		// fmt.Println(fmt.Sprintf("pos: %2d", pos), string(runeNow), inStringDebug(runeNow), " token:", tokenAddedInForLoop)
+		// fmt.Println(fmt.Sprintf("pos: %2d", pos), string(byteNow), inStringDebug(byteNow), " token:", tokenAddedInForLoop)
 
 	} // for, tokenTable
-	return tokenTable
+
+	// the section can end in the middle of an unknown block (a single number, for example: "42"),
+	// it is not closed by whitespace or a structural char.
+	if inUnknownBlock() {
+		tokenAdd('?', posUnknownBlockStart, posTo-1)
+	}
+	return tokenTable, posStringStart
 }
 
 
-func stepB__JSON_validation_L1(tokenTable tokenElems) []error {
-	// find incorrect {..} [..] pairs,
+func stepB__JSON_validation_L1(src []byte, tokenTable tokenElems, opts Options) []error { // TESTED
+	/* grammar check over the token table, without recursion (json.org / RFC 8259):
+	   - {} [] pairing,
+	   - missing/extra commas and colons, trailing commas,
+	   - object keys have to be strings,
+	   - invalid literals ('?' tokens), invalid numbers (012, 1., 0x1f), unclosed strings ('U' tokens),
+	   - invalid utf8 bytes, invalid escapes and control chars in strings,
+	   - duplicated object keys, with opts.DuplicateKeys == DuplicateKeysError,
+	   - opts.Limits: depth, string length, array length, object members, num of errors.
+	   - only one root value is allowed, nothing can be after that.
+
+	   With opts.Relaxed, trailing commas and identifier keys are accepted (JSON5),
+	   and the comments have to be removed from the token table before this step.
+
+	   After an error the validation goes on (as if the expected token had been there),
+	   so more than one problem can be reported from one src - except an exceeded limit:
+	   the validation is stopped, the src is not processed further.
+	*/
 	errorsCollected := []error{}
-	tokenOpeners := []rune{}
+	positionCounter := newSrcPositionCounter(src) // the errors are found in src order, the src is read only once
+
+	errAdd := func(kind error, msg string, token tokenElem) {
+		errorsCollected = append(errorsCollected, positionCounter.parseError(token.posInSrcFirst, token.tokenType, kind, msg))
+	}
 
-	errMsg := func (tokenType rune, pos int) string {
-		posTxt := strconv.Itoa(pos)
-		if tokenType == '}' { return "unpaired Object closer, charPosInFile: " + posTxt }
-		//if tokenType == ']' {  }
-		return "unpaired Array closer, charPosInFile: " + posTxt
+	limitExceeded := false
+	limitCheck := func(limitName string, limit, value int, token tokenElem) {
+		if limit_is_exceeded(limit, value) && !limitExceeded {
+			errAdd(ErrLimitExceeded, limit_exceeded_msg(limitName, limit), token)
+			limitExceeded = true
+		}
+	}
+
+	containers := []tokenElem{} // the actually open { and [ tokens. the last one is the innermost container
+	containersLen := []int{}    // num of elems/keys in the open containers, for opts.Limits
+	containerLast := func() rune {
+		if len(containers) == 0 {
+			return '?' // no open container, we are on root level
+		}
+		return containers[len(containers)-1].tokenType
 	}
-	_ = errMsg('"', 0)
 
-	lastTokenOpener := func() rune {
-		if len(tokenOpeners) == 0 {
-			return '?' // no real content, return with unknown token type
+	// a new elem in the innermost container: a value in an array, or a key in an object
+	containerChildAdd := func(token tokenElem) {
+		if len(containersLen) == 0 {
+			return
+		}
+		containersLen[len(containersLen)-1]++
+		if containers[len(containers)-1].tokenType == '[' {
+			limitCheck("MaxArrayLen", opts.Limits.MaxArrayLen, containersLen[len(containersLen)-1], token)
 		} else {
-			return tokenOpeners[len(tokenOpeners)-1]
+			limitCheck("MaxObjectMembers", opts.Limits.MaxObjectMembers, containersLen[len(containersLen)-1], token)
 		}
 	}
 
-	for _, token := range tokenTable {
-		if token.tokenType == '[' || token.tokenType == '{' {
-			tokenOpeners = append(tokenOpeners, token.tokenType)
+	/* what is wanted as the next token:
+	   V  value, or ] - right after an array opener
+	   v  value       - after a colon, or after a comma in an array, or the root value
+	   K  key, or }   - right after an object opener
+	   k  key         - after a comma in an object
+	   :  colon       - after an object key
+	   ,  comma, or the closer of the actual container - after a value
+	   e  end of src  - the root value is complete                       */
+	wanted := 'v'
+
+	afterValue := func() {
+		if len(containers) == 0 {
+			wanted = 'e'
 		} else {
+			wanted = ','
+		}
+	}
+
+	// the JSON number grammar: no leading zeros, no hexa, no + sign, digits on both sides of the dot.
+	// A grammatically valid number out of the float64 range (1e400) is valid only with NumbersRaw
+	numberToken_is_valid := func(token tokenElem) bool {
+		return numberValueParsing_literal_is_valid_L2(string(base__read_sourceCode_section_basedOnTokenPositions(src, token, false)), opts)
+	}
+
+	valueProcess := func(token tokenElem) {
+		if token.tokenType == '?' || token.tokenType == 'i' {
+			errAdd(ErrInvalidLiteral, "invalid literal (only true, false, null, numbers and strings are accepted)", token)
+		} else if token.tokenType == '0' && !numberToken_is_valid(token) {
+			errAdd(ErrInvalidLiteral, "invalid number: "+string(base__read_sourceCode_section_basedOnTokenPositions(src, token, false)), token)
+		} else if token.tokenType == 'U' {
+			errAdd(ErrUnclosedString, "unclosed string", token)
+		}
+
+		if containerLast() == '[' {
+			containerChildAdd(token)
+		}
+
+		if token.tokenType == '{' || token.tokenType == '[' {
+			containers = append(containers, token)
+			containersLen = append(containersLen, 0)
+			limitCheck("MaxDepth", opts.Limits.MaxDepth, len(containers), token)
+			wanted = 'V'
+			if token.tokenType == '{' {
+				wanted = 'K'
+			}
+		} else {
+			afterValue()
+		}
+	}
+
+	closerProcess := func(token tokenElem) {
+		opener := '['
+		closerName := "Array closer"
+		if token.tokenType == '}' {
+			opener = '{'
+			closerName = "Object closer"
+		}
+
+		if containerLast() == opener {
+			containers = containers[:len(containers)-1] // remove the last elem
+			containersLen = containersLen[:len(containers)]
+			afterValue()
+			return
+		}
+
+		errAdd(ErrUnpairedCloser, "unpaired " + closerName, token)
+
+		// if the pair of the closer is in a deeper level, the containers between them are unclosed
+		for posOpener := len(containers) - 1; posOpener >= 0; posOpener-- {
+			if containers[posOpener].tokenType == opener {
+				containers = containers[:posOpener]
+				containersLen = containersLen[:len(containers)]
+				afterValue()
+				return
+			}
+		} // else the closer is simply ignored
+	}
+
+	isValueStart := func(tokenType rune) bool {
+		return tokenType == '"' || tokenType == '0' || tokenType == 't' || tokenType == 'f' || tokenType == 'n' ||
+			   tokenType == '?' || tokenType == 'i' || tokenType == 'U' || tokenType == '{' || tokenType == '['
+	}
+
+	isKey := func(token tokenElem) bool {
+		if opts.Relaxed {
+			return relaxed_key_is_valid(src, token)
+		}
+		return token.tokenType == '"'
+	}
+
+	// DuplicateKeysError: the first positions of the keys of the objects, indexed with the position of the opener
+	objKeysFirst := map[int]map[string]SrcPosition{}
+	keyDuplicationCheck := func(token tokenElem) {
+		if opts.DuplicateKeys != DuplicateKeysError || len(containers) == 0 {
+			return
+		}
+		posOpener := containers[len(containers)-1].posInSrcFirst
+		if objKeysFirst[posOpener] == nil {
+			objKeysFirst[posOpener] = map[string]SrcPosition{}
+		}
+		key := objKey_from_token_L2(src, token)
+		if posFirst, isUsed := objKeysFirst[posOpener][key]; isUsed {
+			errAdd(ErrDuplicateKey, fmt.Sprintf("duplicate object key %q, first used at line %d, column %d", key, posFirst.Line, posFirst.Column), token)
+			return
+		}
+		objKeysFirst[posOpener][key] = positionCounter.position(token.posInSrcFirst)
+	}
+
+	for _, token := range tokenTable {
+		tokenType := token.tokenType
+
+		if tokenType == '"' || tokenType == 'U' { // multi-byte utf8 chars can be only in strings
+			if posInvalid := base__utf8_invalid_pos_first(src[token.posInSrcFirst:token.posInSrcLast+1]); posInvalid != -1 {
+				errorsCollected = append(errorsCollected, positionCounter.parseError(token.posInSrcFirst+posInvalid, tokenType, ErrInvalidUTF8, ""))
+			}
+		}
 
-			if token.tokenType == ']' {
-				if lastTokenOpener() == '[' {
-					tokenOpeners = tokenOpeners[:len(tokenOpeners)-1] // remove the last elem
-			 	} else {
-					errorsCollected = append(errorsCollected, errors.New(errMsg(']', token.posInSrcFirst)))
+		if tokenType == '"' {
+			if posInvalid, kind := stringValueParsing_escapes_check_L2(src[token.posInSrcFirst+1:token.posInSrcLast], opts.Relaxed); posInvalid != -1 {
+				errorsCollected = append(errorsCollected, positionCounter.parseError(token.posInSrcFirst+1+posInvalid, tokenType, kind, ""))
+			}
+		}
+
+		if tokenType == '"' || tokenType == 'i' {
+			limitCheck("MaxStringBytes", opts.Limits.MaxStringBytes, token.posInSrcLast-token.posInSrcFirst+1-base__bool_to_int(tokenType == '"')*2, token)
+		}
+
+		if tokenType == '"' && !opts.Relaxed && src[token.posInSrcFirst] == '\'' { // the relaxed stepA is used with Comments, too
+			errAdd(ErrInvalidLiteral, "single quoted string is accepted only in relaxed mode", token)
+		}
+
+		if tokenType == 'C' { // relaxed mode only
+			errAdd(ErrUnclosedComment, "", token)
+			continue
+		}
+
+		if wanted == 'e' {
+			errAdd(ErrUnexpectedToken, "unexpected token after the root value", token)
+			break // one error is enough, the rest of the src is not processed
+		}
+
+		if wanted == 'v' || wanted == 'V' {
+			if isValueStart(tokenType) {
+				valueProcess(token)
+			} else if tokenType == ']' && wanted == 'V' { // empty array: []
+				closerProcess(token)
+			} else if tokenType == ']' && containerLast() == '[' && opts.Relaxed { // trailing comma is accepted
+				closerProcess(token)
+			} else if tokenType == ']' && containerLast() == '[' {
+				errAdd(ErrTrailingComma, "trailing comma before Array closer", token)
+				closerProcess(token)
+			} else if tokenType == '}' && containerLast() == '{' {
+				errAdd(ErrMissingValue, "missing value after colon", token)
+				closerProcess(token)
+			} else if tokenType == '}' || tokenType == ']' {
+				closerProcess(token)
+			} else if tokenType == ',' {
+				errAdd(ErrMissingValue, "missing value before comma", token)
+			} else { // colon
+				errAdd(ErrMissingValue, "unexpected colon, value is missing", token)
+			}
+
+		} else if wanted == 'k' || wanted == 'K' {
+			if isKey(token) {
+				keyDuplicationCheck(token)
+				containerChildAdd(token)
+				wanted = ':'
+			} else if tokenType == '}' && wanted == 'K' { // empty object: {}
+				closerProcess(token)
+			} else if tokenType == '}' && opts.Relaxed { // trailing comma is accepted
+				closerProcess(token)
+			} else if tokenType == '}' {
+				errAdd(ErrTrailingComma, "trailing comma before Object closer", token)
+				closerProcess(token)
+			} else if tokenType == ']' {
+				closerProcess(token)
+			} else if tokenType == ',' {
+				errAdd(ErrMissingKey, "unexpected comma, object key is missing", token)
+			} else if tokenType == ':' {
+				errAdd(ErrMissingKey, "missing object key before colon", token)
+				wanted = 'v'
+			} else if tokenType == 'U' {
+				errAdd(ErrUnclosedString, "unclosed string", token)
+				wanted = ':'
+			} else { // numbers, literals, containers
+				errAdd(ErrKeyNotString, "object key has to be a string", token)
+				if tokenType == '{' || tokenType == '[' {
+					valueProcess(token)
+				} else {
+					wanted = ':'
 				}
 			}
 
-			if token.tokenType == '}' {
-				if lastTokenOpener() == '{' {
-					tokenOpeners = tokenOpeners[:len(tokenOpeners)-1] // remove the last elem
+		} else if wanted == ':' {
+			if tokenType == ':' {
+				wanted = 'v'
+			} else if isValueStart(tokenType) {
+				errAdd(ErrMissingColon, "missing colon after object key", token)
+				valueProcess(token)
+			} else if tokenType == ',' {
+				errAdd(ErrMissingColon, "missing colon and value after object key", token)
+				wanted = 'k'
+			} else { // } ]
+				errAdd(ErrMissingColon, "missing colon and value after object key", token)
+				closerProcess(token)
+			}
+
+		} else if wanted == ',' {
+			if tokenType == ',' {
+				if containerLast() == '{' {
+					wanted = 'k'
+				} else {
+					wanted = 'v'
+				}
+			} else if tokenType == '}' || tokenType == ']' {
+				closerProcess(token)
+			} else if tokenType == ':' {
+				errAdd(ErrUnexpectedToken, "unexpected colon", token)
+				wanted = 'v'
+			} else { // value start
+				errAdd(ErrMissingComma, "missing comma", token)
+				if containerLast() == '{' && (isKey(token) || tokenType == 'U') {
+					wanted = ':' // it is a key in an object
 				} else {
-					errorsCollected = append(errorsCollected, errors.New(errMsg('}', token.posInSrcFirst)))
-					panic("error")
+					valueProcess(token)
 				}
 			}
 		}
+
+		if limitExceeded {
+			return errorsCollected
+		}
+		if opts.Limits.MaxErrors > 0 && len(errorsCollected) >= opts.Limits.MaxErrors { // enough problems are found
+			return limit_errors_cut(errorsCollected, opts.Limits.MaxErrors)
+		}
+	} // for token
+
+	if len(tokenTable) == 0 {
+		errorsCollected = append(errorsCollected, newParseError(src, 0, '?', ErrEmptySrc, ""))
+	}
+
+	// the innermost container is reported first, but the positions are counted forward
+	errorsUnclosed := make([]error, len(containers))
+	for posOpener, opener := range containers {
+		msg := "unclosed Array"
+		if opener.tokenType == '{' {
+			msg = "unclosed Object"
+		}
+		errorsUnclosed[len(containers)-1-posOpener] = positionCounter.parseError(opener.posInSrcFirst, opener.tokenType, ErrUnclosedContainer, msg)
 	}
-	return errorsCollected
+	return limit_errors_cut(append(errorsCollected, errorsUnclosed...), opts.Limits.MaxErrors)
 }
 
 
+// a container of stepC, that is not closed yet
+type containerBuilding struct {
+	elem      JSON_value
+	posOpener int
+	posStart  SrcPosition // Options.Positions: the position of the opener
+
+	keyWanted   bool   // in an object, after { and comma the next token is a key
+	objKey      string // the key of the actual child in an object
+	posChildKey int    // the position of the key of the actual child - in an array, the first token of the child
+	objKeySpan  SrcSpan // Options.Positions: the span of the key of the actual child
+
+	objKeysCollected map[string]bool // DuplicateKeysCollect: the keys where the values are collected already
+}
+
 // L1: Level 1. A higher level is a more general fun, a lower level is a tool, lib func, or something small
-func stepC__JSON_structure_building__L1(src []rune, tokensTable tokenElems, tokenPosStart int, errorsCollected []error) (JSON_value, int) { // TESTED
+// The structure is built without recursion, the open containers are in a stack:
+// a deeply nested src cannot overflow the Go stack. The value that starts at tokenPosStart is built,
+// and the position of its last token is returned
+func stepC__JSON_structure_building__L1(src []byte, tokensTable tokenElems, tokenPosStart int, errorsCollected []error, opts Options) (JSON_value, int) { // TESTED
 	if tokenPosStart >= len(tokensTable) {
 		errorsCollected= append(errorsCollected, errors.New("wanted position index is higher than tokensTable"))
 	}
@@ -265,103 +613,163 @@ func stepC__JSON_structure_building__L1(src []rune, tokensTable tokenElems, toke
 		return JSON_value{}, 0
 	}
 	elem := JSON_value{}
+	containers := []containerBuilding{}
 	var pos int
 
+	var positions *srcPositionCounter
+	if opts.Positions {
+		positions = newSrcPositionCounter(src)
+	}
+
 	for pos = tokenPosStart; pos<len(tokensTable); pos++ {
 		tokenNow := tokensTable[pos]
+		var parent *containerBuilding // the pointer is used only before the next append/removal in containers
+		if len(containers) > 0 {
+			parent = &containers[len(containers)-1]
+		}
 
-		if tokenNow.tokenType == '"' {
-			elem = NewString__rawToInterpreted__QuotedBothEnd(base__read_sourceCode_section_basedOnTokenPositions(src, tokensTable[pos], false), errorsCollected)
-			break
+		if parent != nil && parent.keyWanted && tokenNow.tokenType != '}' {
+			// the next key, the objKey is not quoted, but interpreted, too
+			parent.objKey = objKey_from_token_L2(src, tokenNow)
+			parent.posChildKey = pos
+			parent.keyWanted = false
+			if opts.Positions {
+				parent.objKeySpan = positions.span(tokenNow)
+			}
+			continue
+		}
+		posValueFirst := pos
 
-		} else if tokenNow.tokenType == '0' { // general number detection
-			textInSrc := base__read_sourceCode_section_basedOnTokenPositions(src, tokensTable[pos], false)
-			// detect simple integers first
-			i, err := strconv.Atoi(string(textInSrc))  // it can't interpret Scientific nums!
-			if err == nil { // it was really an integer...
-				elem = NewNumInt(i)
-				break
+		if tokenNow.tokenType == ':' {
+			continue
+
+		} else if tokenNow.tokenType == ',' {
+			parent.keyWanted = parent.elem.ValType == '{'
+			continue
+
+		} else if tokenNow.tokenType == '{' || tokenNow.tokenType == '[' {
+			if parent != nil && parent.elem.ValType == '[' {
+				parent.posChildKey = pos
+			}
+			container := containerBuilding{elem: NewArr(), posOpener: pos}
+			if tokenNow.tokenType == '{' {
+				container = containerBuilding{elem: NewObj(), posOpener: pos, keyWanted: true}
+			}
+			if opts.Positions {
+				container.posStart = positions.position(tokenNow.posInSrcFirst)
 			}
+			containers = append(containers, container)
+			continue
 
-			f, err := strconv.ParseFloat(string(textInSrc), 64)
-			if err == nil { // it was really an integer...
-				elem = NewNumFloat(f)
+		} else if tokenNow.tokenType == '}' || tokenNow.tokenType == ']' {
+			if parent == nil { // the start position is a closer, there is no value
 				break
 			}
+			elem = parent.elem
+			if opts.Comments {
+				elem.Comments = opts.comments.of_container_inside(parent.posOpener, pos)
+			}
+			if opts.Positions {
+				elem.Pos = &SrcSpans{Value: SrcSpan{Start: parent.posStart, End: positions.position(tokenNow.posInSrcLast + 1)}}
+			}
+			posValueFirst = parent.posOpener
+			containers = containers[:len(containers)-1]
+
+		} else { // strings, numbers, true, false, null
+			elem = stepC__scalar_building_L2(src, tokenNow, opts)
+			if opts.Positions {
+				elem.Pos = &SrcSpans{Value: positions.span(tokenNow)}
+			}
+			if parent != nil && parent.elem.ValType == '[' {
+				parent.posChildKey = pos
+			}
+		}
 
-			// worst case, I don't know what is this, so insert it as a string
-			elem = NewString__rawToInterpreted__QuotedBothEnd(base__read_sourceCode_section_basedOnTokenPositions(src, tokensTable[pos], false), errorsCollected)
+		// the elem is complete: it is the result, or a child of the actual container
+		if len(containers) == 0 {
 			break
+		}
+		parent = &containers[len(containers)-1]
+		if opts.Comments {
+			elem.Comments = append(elem.Comments, opts.comments.of_child(tokensTable, parent.posChildKey, posValueFirst, pos)...)
+		}
+		if parent.elem.ValType == '{' {
+			if opts.Positions {
+				elem.Pos.Key = parent.objKeySpan
+			}
+			parent.objKeysCollected = objValue_add_L2(parent.elem, parent.objKey, elem, opts.DuplicateKeys, parent.objKeysCollected)
+		} else {
+			parent.elem.ValArray = append(parent.elem.ValArray, elem)
+		}
+	} // for BIG loop
 
-		} else if tokenNow.tokenType == '{' {
-				elem = NewObj()
+	return elem, pos // ret with last used position
+}
 
-				for ; pos <len(tokensTable); { // detect children
-					pos, _ = token_find_next__L2(true, []rune{'"'}, pos+1, tokensTable)
+// the token is validated in stepB
+func stepC__scalar_building_L2(src []byte, token tokenElem, opts Options) JSON_value { // TESTED
+	if token.tokenType == '"' {
+		return NewString__rawToInterpreted__QuotedBothEnd(base__read_sourceCode_section_basedOnTokenPositions(src, token, false))
 
-					// the next string key, the objKey is not quoted, but interpreted, too
-					objKey := stringValueParsing_rawToInterpretedCharacters_L2(base__read_sourceCode_section_basedOnTokenPositions(src, tokensTable[pos], true), errorsCollected)
+	} else if token.tokenType == '0' { // general number detection
+		textInSrc := base__read_sourceCode_section_basedOnTokenPositions(src, token, false)
+		if opts.NumbersRaw && numberValueParsing_literal_is_json_L2(string(textInSrc)) { // 1e400 is kept, too
+			numberValue, _ := numberValueParsing_textToNumber_raw_L2(string(textInSrc))
+			return numberValue
+		}
+		numberValue, isNumber := numberValueParsing_textToNumber_L2(string(textInSrc))
+		if opts.Relaxed {
+			numberValue, isNumber = numberValueParsing_textToNumber_relaxed_L2(string(textInSrc))
+		}
+		if !isNumber { // stepB rejects it, never built without an error
+			return JSON_value{ValType: '?', ValRunes: string(textInSrc)}
+		}
+		return numberValue
 
-					// find the next : but don't do anything with that
-					pos, _ = token_find_next__L2(true, []rune{':'}, pos+1, tokensTable)
+	} else if token.tokenType == 't' {
+		return NewBool(true)
 
-					// find the next ANY token, the new VALUE
-					nextValueElem, posLastUsed := stepC__JSON_structure_building__L1(src, tokensTable, pos+1, errorsCollected)
-					elem.ValObject[objKey] = nextValueElem
-					pos = posLastUsed
+	} else if token.tokenType == 'f' {
+		return NewBool(false)
 
-					if pos+1 < len(tokensTable) { // look forward:
-						if tokensTable[pos+1].tokenType == '}' {
-							break
-						}
-					}
-					pos, _ = token_find_next__L2(true, []rune{','}, pos+1, tokensTable)
-				} // for pos, internal children loop
-
-		} else if tokenNow.tokenType == '[' {
-			elem = NewArr()
-			for ; pos < len(tokensTable);  { // detect children
-				// find the next ANY token, the new VALUE
-				nextValueElem, posLastUsed := stepC__JSON_structure_building__L1(src, tokensTable, pos+1, errorsCollected)
-				elem.ValArray = append(elem.ValArray, nextValueElem)
-				pos = posLastUsed
-
-				if pos+1 < len(tokensTable) { // look forward:
-					if tokensTable[pos+1].tokenType == ']' { // if the next elem is ], this is the last child,
-						break // and stop the children detection, leave the detection for loop
-					}
-				}
-				pos, _ = token_find_next__L2(true, []rune{','}, pos+1, tokensTable)
-			} // for pos, internal children loop
+	} else if token.tokenType == 'n' {
+		return NewNull()
+	}
+	return JSON_value{}
+}
 
-		} else if tokenNow.tokenType == '}' { break   // ascii:125,
-		} else if tokenNow.tokenType == ']' { break   // elem prepared, exit
 
-		} else if tokenNow.tokenType == 't' {
-			elem = NewBool(true)
-			break
 
-		} else if tokenNow.tokenType == 'f' {
-			elem = NewBool(false)
-			break
+// the key of an object: the interpreted string, or in relaxed mode the identifier
+func objKey_from_token_L2(src []byte, token tokenElem) string { // TESTED
+	if token.tokenType == '"' {
+		return stringValueParsing_rawToInterpretedCharacters_L2(base__read_sourceCode_section_basedOnTokenPositions(src, token, true))
+	}
+	return string(base__read_sourceCode_section_basedOnTokenPositions(src, token, false))
+}
 
-		} else if tokenNow.tokenType == 'n' {
-			elem = NewNull()
-			break
+// insert the value into the object, with the duplicate key policy.
+// keysCollected: with DuplicateKeysCollect, the keys where the value is an array of the collected values already
+func objValue_add_L2(obj JSON_value, key string, value JSON_value, policy DuplicateKeyPolicy, keysCollected map[string]bool) map[string]bool { // TESTED
+	valueBefore, isUsed := obj.ValObject[key]
+	if !isUsed || policy == DuplicateKeysLastWins || policy == DuplicateKeysError { // Error: reported in stepB
+		obj.valObject_set(key, value)
+		return keysCollected
+	}
+	if policy == DuplicateKeysCollect {
+		if keysCollected == nil {
+			keysCollected = map[string]bool{}
 		}
-
-		/*	This is not possible anymore, every possible token type is detected now
-			} else if tokenNow.tokenType == '?' {
-				elem = NewString__rawToInterpreted__QuotedBothEnd("\"unknown_elem, maybe number or bool\"", errorsCollected)
-				break
-		*/
-	} // for BIG loop
-
-	return elem, pos // ret with last used position
+		if !keysCollected[key] {
+			valueBefore = JSON_value{ValType: '[', ValArray: []JSON_value{valueBefore}, Pos: valueBefore.Pos} // Options.Positions: the first key and value
+			keysCollected[key] = true
+		}
+		valueBefore.ValArray = append(valueBefore.ValArray, value)
+		obj.valObject_set(key, valueBefore)
+	} // DuplicateKeysFirstWins: the value before is kept
+	return keysCollected
 }
 
-
-
 // return with pos only to avoid elem copy with reading/passing
 // find the next token from allowed types
 // one token, or more than one token can be searched
@@ -400,10 +808,130 @@ func token_find_next__L2(wantSomethingFromTypes bool, types []rune, posActual in
 }
 
 
+// number token -> Integer or Float value
+func numberValueParsing_textToNumber_L2(textInSrc string) (JSON_value, bool) { // TESTED
+	// detect simple integers first
+	i, err := strconv.Atoi(textInSrc)  // it can't interpret Scientific nums!
+	if err == nil { // it was really an integer...
+		return NewNumInt(i), true
+	}
+
+	isIntOverflow := errors.Is(err, strconv.ErrRange)
+
+	f, err := strconv.ParseFloat(textInSrc, 64)
+	if err == nil { // it was really a float...
+		value := NewNumFloat(f)
+		if isIntOverflow && numberValueParsing_literal_is_json_L2(textInSrc) { // the exact value is not lost
+			value.ValNumberRaw = textInSrc
+		}
+		return value, true
+	}
+	return JSON_value{}, false
+}
+
+
 // set the string value from raw strings
 // in orig soure code, \n means 2 chars: a backslash and 'n'.
 // but if it is interpreted, that is one newline "\n" char.
-func stringValueParsing_rawToInterpretedCharacters_L2(srcRunes []rune, errorsCollected []error) string{ // TESTED
+// the inverse of the string parsing: the quoted, escaped JSON form of a string value.
+// the quote, the backslash and the control chars are always escaped
+func stringValueRepr_interpretedToRaw_L2(text string, ensureASCII, htmlSafe bool) string { // TESTED
+	isEscapingNeeded := false
+	for pos := 0; pos < len(text); pos++ {
+		byteActual := text[pos]
+		if byteActual < 0x20 || byteActual == '"' || byteActual == '\\' || byteActual >= 0x80 ||
+			(htmlSafe && (byteActual == '<' || byteActual == '>' || byteActual == '&')) {
+			isEscapingNeeded = true
+			break
+		}
+	}
+	if !isEscapingNeeded { // the most common case
+		return "\"" + text + "\""
+	}
+
+	hexaDigits := "0123456789abcdef"
+	escapeU := func(out []byte, code rune) []byte {
+		return append(out, '\\', 'u', hexaDigits[code>>12&0xf], hexaDigits[code>>8&0xf], hexaDigits[code>>4&0xf], hexaDigits[code&0xf])
+	}
+
+	out := make([]byte, 0, len(text)+8)
+	out = append(out, '"')
+	for _, runeActual := range text { // an invalid utf8 byte is read as U+FFFD
+		switch {
+		case runeActual == '"':
+			out = append(out, '\\', '"')
+		case runeActual == '\\':
+			out = append(out, '\\', '\\')
+		case runeActual == '\n':
+			out = append(out, '\\', 'n')
+		case runeActual == '\r':
+			out = append(out, '\\', 'r')
+		case runeActual == '\t':
+			out = append(out, '\\', 't')
+		case runeActual == '\b':
+			out = append(out, '\\', 'b')
+		case runeActual == '\f':
+			out = append(out, '\\', 'f')
+		case runeActual < 0x20:
+			out = escapeU(out, runeActual)
+		case htmlSafe && (runeActual == '<' || runeActual == '>' || runeActual == '&' || runeActual == '\u2028' || runeActual == '\u2029'):
+			out = escapeU(out, runeActual)
+		case ensureASCII && runeActual > 0xffff:
+			runeHigh, runeLow := utf16.EncodeRune(runeActual)
+			out = escapeU(escapeU(out, runeHigh), runeLow)
+		case ensureASCII && runeActual >= 0x80:
+			out = escapeU(out, runeActual)
+		default:
+			out = utf8.AppendRune(out, runeActual)
+		}
+	}
+	return string(append(out, '"'))
+}
+
+// the first invalid escape or unescaped control char in the content of a string (without quotes).
+// -1 if the content is valid. In relaxed mode \' can be used, and only the line breaks are forbidden.
+func stringValueParsing_escapes_check_L2(src []byte, isRelaxed bool) (int, error) { // TESTED
+	for pos := 0; pos < len(src); pos++ {
+		byteActual := src[pos]
+		if byteActual < 0x20 && (!isRelaxed || byteActual == '\n' || byteActual == '\r') {
+			return pos, ErrControlCharacter
+		}
+		if byteActual != '\\' {
+			continue
+		}
+
+		byteNext1 := base__srcGetChar__safeOverindexing(src, pos+1)
+		if byteNext1 == 'u' {
+			if _, isHexa := base__hexa4_to_intVal(src, pos+2); !isHexa {
+				return pos, ErrInvalidEscape
+			}
+			pos += 1 + 4
+			continue
+		}
+		switch byteNext1 {
+		case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+		case '\'', 'v': // JSON5
+			if !isRelaxed {
+				return pos, ErrInvalidEscape
+			}
+		case '0': // JSON5: \0 is not followed by a digit
+			if nextDigit := base__srcGetChar__safeOverindexing(src, pos+2); !isRelaxed || (nextDigit >= '0' && nextDigit <= '9') {
+				return pos, ErrInvalidEscape
+			}
+		case 'x': // JSON5: \xHH
+			if _, isHexa := relaxed_hexa2_to_intVal(src, pos+2); !isRelaxed || !isHexa {
+				return pos, ErrInvalidEscape
+			}
+			pos += 2
+		default:
+			return pos, ErrInvalidEscape
+		}
+		pos++
+	}
+	return -1, nil
+}
+
+func stringValueParsing_rawToInterpretedCharacters_L2(src []byte) string{ // TESTED
 
 	/* Tasks:
 	- is it a valid string?
@@ -411,110 +939,107 @@ func stringValueParsing_rawToInterpretedCharacters_L2(srcRunes []rune, errorsCol
 
 	the func works typically with 2 chars, for example: \t
 	but sometime with 6: \u0123, so I need to look forward for the next 5 chars
-	*/
 
+	the src is utf8 bytes: multi-byte chars are copied simply, only the ascii escapes are interpreted.
+	the utf8 validity and the escapes of the string are checked in stepB, the errors are reported there.
+	*/
 
-	// in  base__srcGetChar__safeOverindexing__spaceGivenBackForAllWhitespaces ->  base__srcGetChar__safeOverindexing
-	// the src is read often, as rune. so the string->rune conversion is often used (in the past)
-	// so this conversion is done now, only once, to speed up the process, and runes are passed
+	if bytes.IndexByte(src, '\\') == -1 { // the most common case: nothing to interpret,
+		return string(src)                // the bytes can be used directly
+	}
 
-	valueFromRawSrcParsing := []rune{}
+	valueFromRawSrcParsing := make([]byte, 0, len(src))
 
 	// fmt.Println("string token value detection:", src)
-	runeBackSlash := '\\' // be careful: this is ONE \ char, only written with this expression
+	byteBackSlash := byte('\\') // be careful: this is ONE \ char, only written with this expression
 
 	// pos start + 1: strings has initial " in runes
 	// post end -1  closing " after string content
-	for pos := 0; pos < len(srcRunes); pos++ {
+	for pos := 0; pos < len(src); pos++ {
 
-		runeActual := base__srcGetChar__safeOverindexing__spaceGivenBackForAllWhitespaces(srcRunes, pos)
-		//fmt.Println("rune actual (string value set):", pos, string(runeActual), runeActual)
+		byteActual := src[pos]
 
-		if runeActual != runeBackSlash { // a non-backSlash char
-			valueFromRawSrcParsing = append(valueFromRawSrcParsing, runeActual)
+		if byteActual != byteBackSlash { // a non-backSlash char
+			valueFromRawSrcParsing = append(valueFromRawSrcParsing, byteActual)
 			continue
 		} else {
-			// runeActual is \\ here, so ESCAPING started
-			runeNext1 := base__srcGetChar__safeOverindexing__spaceGivenBackForAllWhitespaces(srcRunes, pos+1)
+			// byteActual is \\ here, so ESCAPING started
+			byteNext1 := base__srcGetChar__safeOverindexing(src, pos+1)
 
-			if runeNext1 == 'u' {
+			if byteNext1 == 'u' {
 				// this is \u.... unicode code point - special situation,
 				// because after the \u four other chars has to be handled
-
-				runeNext2 := base__srcGetChar__safeOverindexing__spaceGivenBackForAllWhitespaces(srcRunes, pos+2)
-				runeNext3 := base__srcGetChar__safeOverindexing__spaceGivenBackForAllWhitespaces(srcRunes, pos+3)
-				runeNext4 := base__srcGetChar__safeOverindexing__spaceGivenBackForAllWhitespaces(srcRunes, pos+4)
-				runeNext5 := base__srcGetChar__safeOverindexing__spaceGivenBackForAllWhitespaces(srcRunes, pos+5)
-
-				base10_val_2, err2 := base__hexaRune_to_intVal(runeNext2)
-				if err2 != nil {
-					errorsCollected = append(errorsCollected, err2)
-				}
-
-				base10_val_3, err3 := base__hexaRune_to_intVal(runeNext3)
-				if err3 != nil {
-					errorsCollected = append(errorsCollected, err3)
-				}
-
-				base10_val_4, err4 := base__hexaRune_to_intVal(runeNext4)
-				if err4 != nil {
-					errorsCollected = append(errorsCollected, err4)
+				codePoint, isHexa := base__hexa4_to_intVal(src, pos+2)
+				if !isHexa { // not validated src: the escape is kept as it is
+					valueFromRawSrcParsing = append(valueFromRawSrcParsing, byteBackSlash)
+					continue
 				}
+				pos += 1 + 4 // one extra pos because of the u, and +4 because of the digits
 
-				base10_val_5, err5 := base__hexaRune_to_intVal(runeNext5)
-				if err5 != nil {
-					errorsCollected = append(errorsCollected, err5)
+				// a char outside of the BMP is written as an UTF-16 surrogate pair: \ud83d\ude00
+				if utf16.IsSurrogate(rune(codePoint)) && base__srcGetChar__safeOverindexing(src, pos+1) == byteBackSlash && base__srcGetChar__safeOverindexing(src, pos+2) == 'u' {
+					codePointLow, isHexaLow := base__hexa4_to_intVal(src, pos+3)
+					if runeCombined := utf16.DecodeRune(rune(codePoint), rune(codePointLow)); isHexaLow && runeCombined != utf8.RuneError {
+						codePoint = int(runeCombined)
+						pos += 6
+					}
 				}
+				// a lone surrogate is not a valid char, it is replaced with U+FFFD
+				valueFromRawSrcParsing = utf8.AppendRune(valueFromRawSrcParsing, rune(codePoint))
 
-				unicodeVal_10Based := 0
-
-				if err2 == nil && err3 == nil && err4 == nil && err5 == nil {
-					unicodeVal_10Based = base10_val_2*16*16*16 +
-						base10_val_3*16*16 +
-						base10_val_4*16 +
-						base10_val_5
-				}
-				runeFromHexaDigits := rune(unicodeVal_10Based)
+			} else if codePoint, isHexa := relaxed_hexa2_to_intVal(src, pos+2); byteNext1 == 'x' && isHexa { // JSON5: \xHH is U+00HH
+				pos += 1 + 2
+				valueFromRawSrcParsing = utf8.AppendRune(valueFromRawSrcParsing, rune(codePoint))
 
-				pos += 1 + 4 // one extra pos because of the u, and +4 because of the digits
-				valueFromRawSrcParsing = append(valueFromRawSrcParsing, runeFromHexaDigits)
+			} else if byteNext1 == '0' { // JSON5: \0, the NUL char
+				pos += 1
+				valueFromRawSrcParsing = append(valueFromRawSrcParsing, 0)
 
 			} else { // the first detected char was a backslash, what is the second?
 				// so this is a simple escaped char, for example: \" \t \b \n
-				runeReal := '?'
-				if runeNext1 == '"' { // \" -> is a " char in a string
-					runeReal = '"' // in a string, this is an escaped " double quote char
+				byteReal := byte(0) // unknown escape, it is reported in stepB
+				if byteNext1 == '"' { // \" -> is a " char in a string
+					byteReal = '"' // in a string, this is an escaped " double quote char
+				} else
+				if byteNext1 == '\'' { // \' in a single quoted string, relaxed mode (JSON5)
+					byteReal = '\''
 				} else
-				if runeNext1 == runeBackSlash { // in reality, these are the 2 chars: \\
-					runeReal = '\\' // reverse solidus
+				if byteNext1 == byteBackSlash { // in reality, these are the 2 chars: \\
+					byteReal = '\\' // reverse solidus
 				} else
-				if runeNext1 == '/' { // a very special escaping: \/
-					runeReal = '/' // solidus
+				if byteNext1 == '/' { // a very special escaping: \/
+					byteReal = '/' // solidus
 				} else
-				if runeNext1 == 'b' { // This is the first good example for escaping:
-					runeReal = '\b' // in the src there were 2 chars: \ and b,
+				if byteNext1 == 'b' { // This is the first good example for escaping:
+					byteReal = '\b' // in the src there were 2 chars: \ and b,
 				} else //  (backspace)    // and one char is inserted into the stringVal
-				if runeNext1 == 'f' { // formfeed
-					runeReal = '\f'
+				if byteNext1 == 'f' { // formfeed
+					byteReal = '\f'
 				} else
-				if runeNext1 == 'n' { // linefeed
-					runeReal = '\n'
+				if byteNext1 == 'n' { // linefeed
+					byteReal = '\n'
 				} else
-				if runeNext1 == 'r' { // carriage return
-					runeReal = '\r' //
+				if byteNext1 == 'r' { // carriage return
+					byteReal = '\r' //
 				} else
-				if runeNext1 == 't' { // horizontal tab
-					runeReal = '\t' //
+				if byteNext1 == 't' { // horizontal tab
+					byteReal = '\t' //
+				} else
+				if byteNext1 == 'v' { // vertical tab, relaxed mode (JSON5)
+					byteReal = '\v'
 				}
 
+				if byteReal == 0 || pos+1 >= len(src) { // not validated src: the unknown escape is kept as it is
+					valueFromRawSrcParsing = append(valueFromRawSrcParsing, byteBackSlash)
+					continue
+				}
 				pos += 1 // one extra pos increasing is necessary, because of
 				// 2 chars were processed: the actual \ and the next one.
 
-				valueFromRawSrcParsing = append(valueFromRawSrcParsing, runeReal)
+				valueFromRawSrcParsing = append(valueFromRawSrcParsing, byteReal)
 			}
 		} // else
 	} // for
 
 	return string(valueFromRawSrcParsing)
 }
-
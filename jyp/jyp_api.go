@@ -12,15 +12,101 @@ package jyp
 
 import (
 	"errors"
+	"math"
 	"strconv"
 	"strings"
 )
 
+// Options tunes the parsing. The zero value is the default, standard behaviour
+type Options struct {
+	// num of goroutines in the tokenization of big sources. 0 or 1: sequential tokenization.
+	// The src is tokenized in parallel only if the chunks are big enough (some hundred Kb),
+	// the result is the same as the sequential one.
+	Parallel int
+
+	// JSON5: comments, trailing commas, single quoted strings, identifier keys,
+	// hexa numbers, Infinity, NaN... see jyp_relaxed.go. Relaxed parsing is always sequential.
+	Relaxed bool
+
+	// JSONC: the comments are accepted and saved into the Comments of the values,
+	// so they can be written back with ReprWithComments(). See jyp_jsonc.go
+	Comments bool
+
+	// every number keeps its literal in ValNumberRaw, and Repr() writes it back unchanged.
+	// big ids, exact decimals: see NumberBigInt(), NumberBigFloat() in jyp_numbers.go
+	NumbersRaw bool
+
+	// what happens if an object key is used more than once. The default: the last value is used
+	DuplicateKeys DuplicateKeyPolicy
+
+	// size, depth and length limits for untrusted input, see jyp_limits.go. The default: no limits
+	Limits Limits
+
+	// with errors, the best-effort tree is built, too: the broken nodes are marked. See jyp_recover.go
+	Recover bool
+
+	// the src span of every value and object key is saved in Pos. See PosGetPath() in jyp_positions.go
+	Positions bool
+
+	comments tokenComments // the detected comments, filled internally if Comments is used
+}
+
+type DuplicateKeyPolicy int
+
+const (
+	DuplicateKeysLastWins  DuplicateKeyPolicy = iota // the last value overwrites the earlier ones
+	DuplicateKeysFirstWins                           // the first value is kept, the later ones are dropped
+	DuplicateKeysError                               // ErrDuplicateKey, with the position of both keys
+	DuplicateKeysCollect                             // every value of the key is collected into an array
+)
+
+// ReprOptions tunes the output of ReprWithOptions. The zero value is the compact Repr()
+type ReprOptions struct {
+	// num of spaces in one indentation level. 0: compact, one line output
+	Indent int
+
+	// every non-ASCII char is written with \u escape, a char outside of the BMP with a surrogate pair
+	EnsureASCII bool
+
+	// < > & U+2028 U+2029 are written with \u escape, so the output can be embedded into a html <script>
+	HTMLSafe bool
+
+	// the object keys are written in insertion order (src order after parsing). The default: sorted keys
+	KeysInsertionOrder bool
+}
+
 func JsonParse(srcStr string) (JSON_value, []error) {
-	srcRunes := []rune(srcStr)
-	tokensTableB := stepA__tokensTableDetect_structuralTokens_strings_L1(srcRunes)
-	errorsCollected := stepB__JSON_validation_L1(tokensTableB)
-	elemRoot, _ := stepC__JSON_structure_building__L1(srcRunes, tokensTableB, 0, errorsCollected)
+	return JsonParseBytes([]byte(srcStr))
+}
+
+// the src is processed as utf8 bytes, there is no []rune conversion.
+// the src is not modified, and the parsed values don't refer to it
+func JsonParseBytes(src []byte) (JSON_value, []error) {
+	return JsonParseBytesWithOptions(src, Options{})
+}
+
+func JsonParseWithOptions(srcStr string, opts Options) (JSON_value, []error) {
+	return JsonParseBytesWithOptions([]byte(srcStr), opts)
+}
+
+func JsonParseBytesWithOptions(src []byte, opts Options) (JSON_value, []error) {
+	if limit_is_exceeded(opts.Limits.MaxInputBytes, len(src)) { // before the tokenization
+		return JSON_value{}, []error{newParseError(src, opts.Limits.MaxInputBytes, '?', ErrLimitExceeded, limit_exceeded_msg("MaxInputBytes", opts.Limits.MaxInputBytes))}
+	}
+	var tokensTableB tokenElems
+	if opts.Comments {
+		tokensTableB, opts.comments = tokensTable_comments_separate(src, stepA__tokensTableDetect_relaxed_L1(src))
+	} else {
+		tokensTableB = opts.tokensTableDetect(src)
+	}
+	errorsCollected := stepB__JSON_validation_L1(src, tokensTableB, opts)
+	if opts.Recover && len(errorsCollected) > 0 {
+		return stepC__JSON_structure_building_recover_L1(src, tokensTableB, errorsCollected, opts), errorsCollected
+	}
+	elemRoot, _ := stepC__JSON_structure_building__L1(src, tokensTableB, 0, errorsCollected, opts)
+	if opts.Comments && len(errorsCollected) == 0 {
+		elemRoot.Comments = append(elemRoot.Comments, opts.comments.of_root(tokensTableB)...)
+	}
 
 	return elemRoot, errorsCollected
 }
@@ -39,9 +125,21 @@ func (v JSON_value) Repr(indentationLength ...int) string {
 	return v.Repr_tuned(indentation, 0)
 }
 
+func (v JSON_value) ReprWithOptions(opts ReprOptions) string {
+	indentation := ""
+	if opts.Indent > 0 {
+		indentation = base__prefixGenerator_for_repr(" ", opts.Indent)
+	}
+	return v.repr_options_tuned(indentation, 0, opts)
+}
+
 // tunable repr: with this, tabulator can be used for example instead of spaces as indent,
 // level 0 means left align - if higher level is used, the output will be moved to right on the screen
 func (v JSON_value) Repr_tuned(indent string, level int) string {
+	return v.repr_options_tuned(indent, level, ReprOptions{})
+}
+
+func (v JSON_value) repr_options_tuned(indent string, level int, opts ReprOptions) string {
 	prefix := "" // indentOneLevelPrefix
 	prefix2 := "" // indentTwoLevelPrefix
 	newLine := ""
@@ -55,7 +153,11 @@ func (v JSON_value) Repr_tuned(indent string, level int) string {
 	}
 
 	if v.ValType == '"' {
-		return "\""+v.ValRunes + "\""
+		return stringValueRepr_interpretedToRaw_L2(v.ValRunes, opts.EnsureASCII, opts.HTMLSafe)
+	} else
+
+	if (v.ValType == 'I' || v.ValType == 'F') && v.ValNumberRaw != "" {
+		return v.ValNumberRaw
 	} else
 
 	if v.ValType == 'I' {
@@ -63,6 +165,9 @@ func (v JSON_value) Repr_tuned(indent string, level int) string {
 	} else
 
 	if v.ValType == 'F' {
+		if math.IsInf(v.ValNumberFloat, 0) || math.IsNaN(v.ValNumberFloat) { // relaxed Infinity, NaN: no JSON form,
+			return "null"                                                      // as in JavaScript JSON.stringify
+		}
 		return strconv.FormatFloat(v.ValNumberFloat, 'f', -1, 64)
 	} else
 
@@ -73,16 +178,16 @@ func (v JSON_value) Repr_tuned(indent string, level int) string {
 		return "false"
 	} else
 
-	if v.ValType == 'n' {
+	if v.ValType == 'n' || v.ValType == '?' { // '?': a broken node of the recovered tree
 		return "null"
 	} else
 
 	if v.ValType == '{' {
 		out := prefix + "{" + newLine
-		for counter, childKey := range v.ValObject_keys_sorted() {
+		for counter, childKey := range v.valObject_keys(opts.KeysInsertionOrder) {
 			comma := base__separator_set_if_no_last_elem(counter, len(v.ValObject), ",")
 			childVal := v.ValObject[childKey]
-			out += prefix2 + "\""+childKey+"\"" + colon + childVal.Repr_tuned(indent, level+1) + comma + newLine
+			out += prefix2 + stringValueRepr_interpretedToRaw_L2(childKey, opts.EnsureASCII, opts.HTMLSafe) + colon + childVal.repr_options_tuned(indent, level+1, opts) + comma + newLine
 		}
 		out += prefix + "}"
 		return out
@@ -92,7 +197,7 @@ func (v JSON_value) Repr_tuned(indent string, level int) string {
 		out := prefix + "[" + newLine
 		for counter, child := range v.ValArray {
 			comma := base__separator_set_if_no_last_elem(counter, len(v.ValArray), ",")
-			out += prefix2 + indent + child.Repr_tuned(indent, level+1) + comma + newLine
+			out += prefix2 + indent + child.repr_options_tuned(indent, level+1, opts) + comma + newLine
 		}
 		out += prefix + "]"
 		return out
@@ -139,11 +244,11 @@ func NewStr(text string) JSON_value { // TESTED
 }
 
 // raw: the string needs to be interpreted. "a\tb": \t represents 2 chars, it needs to be interpreted.
-func NewString__rawToInterpreted__QuotedBothEnd(text []rune, errorsCollected []error) JSON_value {
+func NewString__rawToInterpreted__QuotedBothEnd(text []byte) JSON_value {
 	// strictly have minimum one "opening....and...one..closing" quote!
 	return JSON_value{
 		ValType:  '"',
-		ValRunes: stringValueParsing_rawToInterpretedCharacters_L2( text[1:len(text)-1], errorsCollected),
+		ValRunes: stringValueParsing_rawToInterpretedCharacters_L2( text[1:len(text)-1]),
 	}
 }
 
@@ -151,6 +256,7 @@ func NewObj() JSON_value { // TESTED
 	return JSON_value{
 		ValType: '{',
 		ValObject: map[string]JSON_value{},
+		ValObjectOrder: map[string]int{},
 	}
 }
 
@@ -200,7 +306,7 @@ func (v JSON_value) SetPath(keysMerged string, value JSON_value, autoCreateChild
 			if err2 != nil {
 				return err2
 			}
-			v.ValObject[keys[0]] = children
+			v.valObject_set(keys[0], children)
 		}
 
 		return nil
@@ -211,9 +317,10 @@ func (v JSON_value) SetPath(keysMerged string, value JSON_value, autoCreateChild
 
 func (v JSON_value) AddKeyVal(key string, value JSON_value) error {
 	if v.ValType ==  '{' {
-		objects := v.ValObject
-		objects[key] = value
-		v.ValObject = objects
+		if valueOld, isKeyUsed := v.ValObject[key]; isKeyUsed && len(value.Comments) == 0 {
+			value.Comments = valueOld.Comments // JSONC: the comments of the replaced value are kept
+		}
+		v.valObject_set(key, value) // a replaced key keeps its place in the insertion order
 		return nil
 	}
 	return errors.New(errorPrefix + "add value into non-object")
@@ -12,7 +12,9 @@ LICENSE file in the root directory of this source tree.
 package jyp
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
 )
 // Negative testcases/errors will be checked in a different file
@@ -87,4 +89,146 @@ func Test_JsonParse(t *testing.T) {
 	compare_int_int(testName, 2, len(root.ValArray), t)          // has 1 elem
 	compare_str_str(testName, "a", root.ValArray[0].ValRunes, t) // has 1 elem
 	compare_str_str(testName, "A", root.ValArray[1].ValRunes, t) // has 1 elem
+
+	testName = funName + "_empty_containers"
+	src = `{"o": {}, "a": [], "n": [[], {}]}`
+	root, _ = JsonParse(src)
+	compare_str_str(testName, `{"a":[],"n":[[],{}],"o":{}}`, root.Repr(), t)
+}
+
+//  go test -v -run Test_JsonParse_errors
+func Test_JsonParse_errors(t *testing.T) {
+	funName := "Test_JsonParse_errors"
+	testName := funName + "_no_panic"
+
+	for _, src := range []string{`}`, `]]`, `{"a" 1}`, `[1 2]`, `{"a":1,}`, `{,}`, `tru`, `"abc`, `[1] 2`, `[012]`, `{"a": 1.}`, `[1e400, 2]`, `{"a": -1e999}`} {
+		root, errorsCollected := JsonParse(src)
+		compare_bool_bool(testName + ": " + src, true, len(errorsCollected) > 0, t)
+		compare_rune_rune(testName + ": " + src, 0, root.ValType, t) // empty value, not a wrongly built tree
+	}
+
+	testName = funName + "_out_of_range_number" // grammatically valid, but it can't be built - only with NumbersRaw
+	for _, opts := range []Options{{}, {Relaxed: true}} {
+		_, errorsCollected := JsonParseWithOptions(`[1e400, 2]`, opts)
+		compare_bool_bool(testName, true, len(errorsCollected) == 1 && errors.Is(errorsCollected[0], ErrInvalidLiteral), t)
+	}
+	root, _ := JsonParseWithOptions(`{"a": -1e999, "b": 1}`, Options{Recover: true})
+	compare_bool_bool(testName, true, root.ValObject["a"].IsBroken(), t)
+	compare_str_str(testName, `{"a":null,"b":1}`, root.Repr(), t)
+}
+
+//  go test -v -run Test_JsonParse_DuplicateKeys
+func Test_JsonParse_DuplicateKeys(t *testing.T) {
+	funName := "Test_JsonParse_DuplicateKeys"
+	src := "{\"a\": 1, \"b\": {\"a\": 0},\n \"\\u0061\": 2, \"a\": [3]}"
+
+	testName := funName + "_last_wins"
+	root, errorsCollected := JsonParse(src)
+	compare_int_int(testName, 0, len(errorsCollected), t)
+	compare_str_str(testName, `{"a":[3],"b":{"a":0}}`, root.Repr(), t)
+
+	testName = funName + "_first_wins"
+	root, errorsCollected = JsonParseWithOptions(src, Options{DuplicateKeys: DuplicateKeysFirstWins})
+	compare_int_int(testName, 0, len(errorsCollected), t)
+	compare_str_str(testName, `{"a":1,"b":{"a":0}}`, root.Repr(), t)
+
+	testName = funName + "_collect"
+	root, errorsCollected = JsonParseWithOptions(src, Options{DuplicateKeys: DuplicateKeysCollect})
+	compare_int_int(testName, 0, len(errorsCollected), t)
+	compare_str_str(testName, `{"a":[1,2,[3]],"b":{"a":0}}`, root.Repr(), t)
+
+	testName = funName + "_error"
+	root, errorsCollected = JsonParseWithOptions(src, Options{DuplicateKeys: DuplicateKeysError})
+	compare_int_int(testName, 2, len(errorsCollected), t) // the nested "a" is in a different object
+	compare_rune_rune(testName, 0, root.ValType, t)
+	compare_bool_bool(testName, true, errors.Is(errorsCollected[0], ErrDuplicateKey), t)
+	compare_int_int(testName, 2, errorsCollected[0].(ParseError).Line, t)
+	compare_int_int(testName, 2, errorsCollected[0].(ParseError).Column, t)
+	compare_bool_bool(testName, true, strings.Contains(errorsCollected[0].Error(), "first used at line 1, column 2"), t)
+	compare_int_int(testName, 15, errorsCollected[1].(ParseError).Column, t)
+
+	testName = funName + "_relaxed_identifier"
+	_, errorsCollected = JsonParseWithOptions(`{a: 1, "a": 2}`, Options{Relaxed: true, DuplicateKeys: DuplicateKeysError})
+	compare_int_int(testName, 1, len(errorsCollected), t)
+}
+
+//  go test -v -run Test_ReprWithOptions_KeysInsertionOrder
+func Test_ReprWithOptions_KeysInsertionOrder(t *testing.T) {
+	funName := "Test_ReprWithOptions_KeysInsertionOrder"
+
+	testName := funName + "_parsed"
+	src := `{"name":"web","version":2,"deps":{"zlib":"1.3","abc":"0.1"},"active":true}`
+	root, errorsCollected := JsonParse(src)
+	compare_int_int(testName, 0, len(errorsCollected), t)
+	compare_str_str(testName, src, root.ReprWithOptions(ReprOptions{KeysInsertionOrder: true}), t)
+	compare_str_str(testName, `{"active":true,"deps":{"abc":"0.1","zlib":"1.3"},"name":"web","version":2}`, root.Repr(), t)
+
+	testName = funName + "_AddKeyVal"
+	root.AddKeyVal("version", NewNumInt(3)) // a replaced key keeps its place
+	root.AddKeyVal("added", NewNull())
+	root.SetPath("/deps/new", NewStr("1.0"), true)
+	compare_str_str(testName, `{"name":"web","version":3,"deps":{"zlib":"1.3","abc":"0.1","new":"1.0"},"active":true,"added":null}`, root.ReprWithOptions(ReprOptions{KeysInsertionOrder: true}), t)
+
+	testName = funName + "_yaml"
+	root, errorsCollected = YamlParse("b: 1\na:\n  z: 2\n  x: 3\n")
+	compare_int_int(testName, 0, len(errorsCollected), t)
+	compare_str_str(testName, "b: 1\na:\n  z: 2\n  x: 3\n", root.ReprYaml(YamlOptions{KeysInsertionOrder: true}), t)
+
+	testName = funName + "_jsonc"
+	root, _ = JsonParseWithOptions("{\"b\": 1, // one\n \"a\": 2}", Options{Comments: true})
+	compare_str_str(testName, "{\n  \"b\": 1, // one\n  \"a\": 2\n}\n", root.ReprWithCommentsOptions(ReprOptions{Indent: 2, KeysInsertionOrder: true}), t)
+}
+
+//  go test -v -run Test_ReprWithOptions
+func Test_ReprWithOptions(t *testing.T) {
+	funName := "Test_ReprWithOptions"
+
+	testName := funName + "_roundtrip"
+	src := `{"quote \"key\"":"back\\slash","lines":"a\nb\tc\u0001","emoji":"\ud83d\ude00"}`
+	root, errorsCollected := JsonParse(src)
+	compare_int_int(testName, 0, len(errorsCollected), t)
+	reprCompact := root.Repr()
+	compare_str_str(testName, `{"emoji":"😀","lines":"a\nb\tc\u0001","quote \"key\"":"back\\slash"}`, reprCompact, t)
+	rootAgain, errorsCollected := JsonParse(root.Repr(2))
+	compare_int_int(testName, 0, len(errorsCollected), t)
+	compare_str_str(testName, reprCompact, rootAgain.Repr(), t)
+
+	testName = funName + "_options"
+	root = NewObj()
+	root.AddKeyVal("ár", NewStr("<b>😀</b>"))
+	compare_str_str(testName, `{"\u00e1r":"\u003cb\u003e\ud83d\ude00\u003c/b\u003e"}`, root.ReprWithOptions(ReprOptions{EnsureASCII: true, HTMLSafe: true}), t)
+	compare_str_str(testName, "{\n  \"ár\": \"<b>😀</b>\"\n}", root.ReprWithOptions(ReprOptions{Indent: 2}), t)
+}
+
+//  go test -v -run Test_JsonParseBytes_utf8
+func Test_JsonParseBytes_utf8(t *testing.T) {
+	funName := "Test_JsonParseBytes_utf8"
+	testName := funName + "_multibyte"
+
+	root, errorsCollected := JsonParseBytes([]byte(`{"név": "Ősz \u00e9 ✓", "emoji": "😀"}`))
+	compare_int_int(testName, 0, len(errorsCollected), t)
+	compare_str_str(testName, "Ősz é ✓", root.ValObject["név"].ValRunes, t)
+	compare_str_str(testName, "😀", root.ValObject["emoji"].ValRunes, t)
+
+	testName = funName + "_surrogate_pair"
+	root, errorsCollected = JsonParse(`["\uD83D\uDE00", "\u00E9"]`)
+	compare_int_int(testName, 0, len(errorsCollected), t)
+	compare_str_str(testName, `["😀","é"]`, root.Repr(), t)
+
+	testName = funName + "_invalid_escape"
+	_, errorsCollected = JsonParse(`{"key": "ab\x"}`)
+	compare_int_int(testName, 1, len(errorsCollected), t)
+	compare_bool_bool(testName, true, errors.Is(errorsCollected[0], ErrInvalidEscape), t)
+	compare_int_int(testName, 11, errorsCollected[0].(ParseError).ByteOffset, t)
+
+	testName = funName + "_control_char"
+	_, errorsCollected = JsonParse("[\"line\nbreak\"]")
+	compare_int_int(testName, 1, len(errorsCollected), t)
+	compare_bool_bool(testName, true, errors.Is(errorsCollected[0], ErrControlCharacter), t)
+	compare_int_int(testName, 7, errorsCollected[0].(ParseError).Column, t)
+
+	testName = funName + "_invalid_utf8"
+	_, errorsCollected = JsonParseBytes([]byte{'[', '"', 'a', 0xFF, '"', ']'})
+	compare_int_int(testName, 1, len(errorsCollected), t)
+	compare_bool_bool(testName, true, errors.Is(errorsCollected[0], ErrInvalidUTF8), t)
 }
@@ -11,8 +11,10 @@ LICENSE file in the root directory of this source tree.
 package jyp
 
 import (
+	"bytes"
 	"errors"
 	"unicode"
+	"unicode/utf8"
 )
 
 func base__hexaRune_to_intVal(hexaChar rune) (int, error) { // TESTED
@@ -33,6 +35,12 @@ func base__hexaRune_to_intVal(hexaChar rune) (int, error) { // TESTED
 		'd': 13,
 		'e': 14,
 		'f': 15,
+		'A': 10,
+		'B': 11,
+		'C': 12,
+		'D': 13,
+		'E': 14,
+		'F': 15,
 	}
 	base10Val, keyInHexaTable := hexaTable[hexaChar]
 	if keyInHexaTable {
@@ -41,6 +49,19 @@ func base__hexaRune_to_intVal(hexaChar rune) (int, error) { // TESTED
 	return 0, errors.New("hexa char(" + string(hexaChar) + ") was not in hexa table")
 }
 
+// the value of the 4 hexa digits from the position, \u0123 for example
+func base__hexa4_to_intVal(src []byte, pos int) (int, bool) { // TESTED
+	value := 0
+	for posDigit := pos; posDigit < pos+4; posDigit++ {
+		digitValue, err := base__hexaRune_to_intVal(rune(base__srcGetChar__safeOverindexing(src, posDigit)))
+		if err != nil {
+			return 0, false
+		}
+		value = value*16 + digitValue
+	}
+	return value, true
+}
+
 func base__is_whitespace_rune(oneRune rune) bool { // TESTED, DEEP-test is not necessary, wrapper func
 	/*  https://stackoverflow.com/questions/29038314/determining-whitespace-in-go
 	'\t', '\n', '\v', '\f', '\r', ' ', U+0085 (NEL), U+00A0 (NBSP).
@@ -48,6 +69,43 @@ func base__is_whitespace_rune(oneRune rune) bool { // TESTED, DEEP-test is not n
 	return unicode.IsSpace(oneRune)
 }
 
+// the JSON whitespaces (RFC 8259), where the src is processed as bytes. \v and \f are not whitespaces in JSON
+func base__is_whitespace_byte(oneByte byte) bool { // TESTED
+	return oneByte == ' ' || oneByte == '\n' || oneByte == '\t' || oneByte == '\r'
+}
+
+// -1 if the bytes are valid utf8, otherwise the position of the first invalid byte
+func base__utf8_invalid_pos_first(src []byte) int { // TESTED
+	if utf8.Valid(src) { // fast check, the most common case
+		return -1
+	}
+	for pos := 0; pos < len(src); {
+		runeNow, size := utf8.DecodeRune(src[pos:])
+		if runeNow == utf8.RuneError && size == 1 {
+			return pos
+		}
+		pos += size
+	}
+	return -1
+}
+
+func base__newline_in(src []byte) bool { // TESTED
+	return bytes.IndexByte(src, '\n') != -1
+}
+
+func base__bool_to_int(value bool) int { // TESTED
+	if value {
+		return 1
+	}
+	return 0
+}
+
+// a Json number starts with a minus sign or with a digit.
+// the token type detection decides with this rune whether an unknown block can be a number
+func base__is_number_start_rune(oneRune rune) bool { // TESTED
+	return oneRune == '-' || (oneRune >= '0' && oneRune <= '9')
+}
+
 
 // repeat the wanted unit prefix a few times
 func base__prefixGenerator_for_repr(oneUnitPrefix string, repeatNum int) string { // TESTED
@@ -62,7 +120,7 @@ func base__prefixGenerator_for_repr(oneUnitPrefix string, repeatNum int) string
 }
 
 // first/last char removal can be important with "strings" if quotes are not important
-func base__read_sourceCode_section_basedOnTokenPositions(src []rune, token tokenElem, removeFirstLastChar bool) []rune{ // TESTED
+func base__read_sourceCode_section_basedOnTokenPositions(src []byte, token tokenElem, removeFirstLastChar bool) []byte{ // TESTED
 	if !removeFirstLastChar {
 		return src[token.posInSrcFirst:token.posInSrcLast+1]
 	}
@@ -86,9 +144,9 @@ func base__separator_set_if_no_last_elem(position, length_numOfAllElems int, sep
 // and if a space is returned, this has NO MEANING in that parse section
 // this fun is NOT used in string detection - and other places whitespaces can be neglected, too
 // getChar, with whitespace replace
-func base__srcGetChar__safeOverindexing__spaceGivenBackForAllWhitespaces(src []rune, pos int) rune { // TESTED, safeOverindexing is DEEP-tested.
+func base__srcGetChar__safeOverindexing__spaceGivenBackForAllWhitespaces(src []byte, pos int) byte { // TESTED, safeOverindexing is DEEP-tested.
 	char := base__srcGetChar__safeOverindexing(src, pos)
-	if base__is_whitespace_rune(char) {
+	if base__is_whitespace_byte(char) {
 		return ' ' // simplify everything. if the char is ANY whitespace char,
 		// return with SPACE, this is not important in the source code parsing
 	}
@@ -103,9 +161,11 @@ because the size of the runes (in bytes) is not constant.
 performance: the string->rune conversion used too often. is it possible to do the conversion once?
 so src has to be a string
 
+later: the string->rune conversion is not used at all, the src is processed as utf8 bytes.
+The structural chars are ascii, multi-byte chars can be only in strings.
 */
 // getChar, no whitespace replace
-func base__srcGetChar__safeOverindexing(src []rune, pos int) rune { //DEEP-TESTED
+func base__srcGetChar__safeOverindexing(src []byte, pos int) byte { //DEEP-TESTED
 	posPossibleMax := len(src) - 1  // if src is empty, max is -1,
 	posPossibleMin := 0             // and the condition cannot be true here:
 	if (pos >= posPossibleMin) && (pos <= posPossibleMax) {
@@ -39,6 +39,8 @@ func Test_hexaRune_to_intVal(t *testing.T) {
 		{'d', true, 13},
 		{'e', true, 14},
 		{'f', true, 15},
+		{'A', true, 10},
+		{'F', true, 15},
 	}
 
 	for _, hexaRuneTestCase := range testElems { // test all possible elems
@@ -55,6 +57,22 @@ func Test_hexaRune_to_intVal(t *testing.T) {
 	compare_bool_bool(testName, true, err != nil, t)
 }
 
+// go test -v -run Test_base__hexa4_to_intVal
+func Test_base__hexa4_to_intVal(t *testing.T) {
+	funName := "Test_base__hexa4_to_intVal"
+	testName := funName + "_base"
+
+	value, isHexa := base__hexa4_to_intVal([]byte(`\u00E9`), 2)
+	compare_bool_bool(testName, true, isHexa, t)
+	compare_int_int(testName, 0xE9, value, t)
+
+	_, isHexa = base__hexa4_to_intVal([]byte(`\u00g9`), 2)
+	compare_bool_bool(testName, false, isHexa, t)
+
+	_, isHexa = base__hexa4_to_intVal([]byte(`\u00`), 2) // too short
+	compare_bool_bool(testName, false, isHexa, t)
+}
+
 // go test -v -run Test_base__is_whitespace_rune
 func Test_base__is_whitespace_rune(t *testing.T) {
 	funName := "Test_base__is_whitespace_rune"
@@ -81,6 +99,60 @@ func Test_base__is_whitespace_rune(t *testing.T) {
 	compare_bool_bool(testName, false, isWhitespace, t)
 }
 
+// go test -v -run Test_base__is_whitespace_byte
+func Test_base__is_whitespace_byte(t *testing.T) {
+	funName := "Test_base__is_whitespace_byte"
+	testName := funName + "_base"
+
+	compare_bool_bool(testName, true, base__is_whitespace_byte('\r'), t)
+	compare_bool_bool(testName, true, base__is_whitespace_byte('\n'), t)
+	compare_bool_bool(testName, true, base__is_whitespace_byte(' '), t)
+	compare_bool_bool(testName, false, base__is_whitespace_byte('.'), t)
+	compare_bool_bool(testName, false, base__is_whitespace_byte(0xC2), t) // first byte of the utf8 NBSP
+	compare_bool_bool(testName, false, base__is_whitespace_byte('\v'), t) // not JSON whitespaces (RFC 8259)
+	compare_bool_bool(testName, false, base__is_whitespace_byte('\f'), t)
+}
+
+// go test -v -run Test_base__utf8_invalid_pos_first
+func Test_base__utf8_invalid_pos_first(t *testing.T) {
+	funName := "Test_base__utf8_invalid_pos_first"
+	testName := funName + "_base"
+
+	compare_int_int(testName, -1, base__utf8_invalid_pos_first([]byte(`"árvíztűrő"`)), t)
+	compare_int_int(testName, 3, base__utf8_invalid_pos_first([]byte{'"', 0xC3, 0xA1, 0xFF, '"'}), t)
+	compare_int_int(testName, 1, base__utf8_invalid_pos_first([]byte{'"', 0xE2, 0x82, '"'}), t) // truncated 3 byte seq
+}
+
+// go test -v -run Test_base__newline_in
+func Test_base__newline_in(t *testing.T) {
+	funName := "Test_base__newline_in"
+	testName := funName + "_base"
+
+	compare_bool_bool(testName, true, base__newline_in([]byte("a\nb")), t)
+	compare_bool_bool(testName, false, base__newline_in([]byte("a\tb\r")), t)
+	compare_bool_bool(testName, false, base__newline_in([]byte{}), t)
+}
+
+// go test -v -run Test_base__bool_to_int
+func Test_base__bool_to_int(t *testing.T) {
+	funName := "Test_base__bool_to_int"
+	testName := funName + "_base"
+
+	compare_int_int(testName, 1, base__bool_to_int(true), t)
+	compare_int_int(testName, 0, base__bool_to_int(false), t)
+}
+
+// go test -v -run Test_base__is_number_start_rune
+func Test_base__is_number_start_rune(t *testing.T) {
+	funName := "Test_base__is_number_start_rune"
+	testName := funName + "_base"
+
+	compare_bool_bool(testName, true, base__is_number_start_rune('-'), t)
+	compare_bool_bool(testName, true, base__is_number_start_rune('0'), t)
+	compare_bool_bool(testName, true, base__is_number_start_rune('9'), t)
+	compare_bool_bool(testName, false, base__is_number_start_rune('+'), t)
+	compare_bool_bool(testName, false, base__is_number_start_rune('t'), t)
+}
 
 func Test_base__prefixGenerator_for_repr(t *testing.T) {
 	funName := "Test_base__prefixGenerator_for_repr"
@@ -98,7 +170,7 @@ func Test_base__read_sourceCode_section(t *testing.T) {
 	funName := "Test_base__read_sourceCode_section"
 	testName := funName + "_base"
 
-	src := []rune(`{"a": "A"}`)
+	src := []byte(`{"a": "A"}`)
 
 	tokensTableB := stepA__tokensTableDetect_structuralTokens_strings_L1(src)
 	sectionReadBack := base__read_sourceCode_section_basedOnTokenPositions(src, tokensTableB[1], false)
@@ -137,22 +209,22 @@ func Test_base__srcGetChar__safeOverindexing__spaceGivenBackForAllWhitespaces(t
 	funName := "Test_base__srcGetChar__safeOverindexing__spaceGivenBackForAllWhitespaces"
 	testName := funName + "_base"
 
-	txt := []rune("ab\ncd\t")
+	txt := []byte("ab\ncd\t")
 
 	charRead := base__srcGetChar__safeOverindexing__spaceGivenBackForAllWhitespaces(txt, 0)
-	compare_rune_rune(testName,'a', charRead, t)
+	compare_rune_rune(testName, 'a', rune(charRead), t)
 
 	charRead = base__srcGetChar__safeOverindexing__spaceGivenBackForAllWhitespaces(txt, 2)
-	compare_rune_rune(testName,' ', charRead, t) // space instead of \n
+	compare_rune_rune(testName, ' ', rune(charRead), t) // space instead of \n
 
 	charRead = base__srcGetChar__safeOverindexing__spaceGivenBackForAllWhitespaces(txt, 9)
-	compare_rune_rune(testName,' ', charRead, t)
+	compare_rune_rune(testName, ' ', rune(charRead), t)
 
 	charRead = base__srcGetChar__safeOverindexing__spaceGivenBackForAllWhitespaces(txt, -2)
-	compare_rune_rune(testName,' ', charRead, t)
+	compare_rune_rune(testName, ' ', rune(charRead), t)
 
 	charRead = base__srcGetChar__safeOverindexing__spaceGivenBackForAllWhitespaces(txt, 5)
-	compare_rune_rune(testName,' ', charRead, t) // space instead of \t
+	compare_rune_rune(testName, ' ', rune(charRead), t) // space instead of \t
 }
 
 
@@ -161,15 +233,15 @@ func Test_base__srcGetChar__safeOverindexing(t *testing.T) {
 	funName := "Test_base__srcGetChar__safeOverindexing"
 	testName := funName + "_base"
 
-	txt := []rune("ab\ncd")
+	txt := []byte("ab\ncd")
 
 	charRead := base__srcGetChar__safeOverindexing(txt, 0)
-	compare_rune_rune(testName,'a', charRead, t)
+	compare_rune_rune(testName, 'a', rune(charRead), t)
 
 	charRead = base__srcGetChar__safeOverindexing(txt, 2)
-	compare_rune_rune(testName,'\n', charRead, t)
+	compare_rune_rune(testName, '\n', rune(charRead), t)
 
 	charRead = base__srcGetChar__safeOverindexing(txt, 8)
-	compare_rune_rune(testName,' ', charRead, t)
+	compare_rune_rune(testName, ' ', rune(charRead), t)
 }
 
@@ -0,0 +1,218 @@
+/*
+Copyright (c) 2024, Balazs Nyiro, balazs.nyiro.ca@gmail.com
+All rights reserved.
+
+This source code (all file in this repo) is licensed
+under the Apache-2 style license found in the
+LICENSE file in the root directory of this source tree.
+
+This module: lossless editing, for hand-formatted files.
+
+	doc, _ := jyp.CstParse(src)
+	err := doc.SetPath("/spec/replicas", jyp.NewNumInt(3), false)
+	err = doc.DeletePath("/spec/debug")
+	os.WriteFile(fileName, doc.Bytes(), 0644)
+
+CstDoc keeps the src and its token table (as LazyDoc), the whitespace is the
+text between the tokens - nothing is lost. An edit rewrites only the byte range
+of the edited value or member, every other byte of the src is kept as it was:
+the indentation, the key order, the spelling of the numbers (1.50, 1e3).
+After an edit the new src is tokenized again, so the next edit can be done.
+
+  - SetPath replaces an existing value. A new key is inserted after the last member
+    of the object, with the separator and the indentation of the members before it.
+  - DeletePath removes the member/elem with its comma.
+  - The new values are written with Repr(), in one line.
+
+In a CstDoc path, an array elem can be selected with its index. If a key is
+duplicated, the last one is edited, as in JSON_value.
+*/
+
+package jyp
+
+import (
+	"errors"
+	"strings"
+)
+
+type CstDoc struct {
+	doc LazyDoc // the src is owned by the CstDoc
+}
+
+func CstParse(srcStr string) (*CstDoc, []error) {
+	return CstParseBytes([]byte(srcStr))
+}
+
+// the src is copied, the edits don't modify the passed slice
+func CstParseBytes(src []byte) (*CstDoc, []error) {
+	doc, errorsCollected := LazyParseBytes(append([]byte{}, src...))
+	if len(errorsCollected) > 0 {
+		return nil, errorsCollected
+	}
+	return &CstDoc{doc: doc}, errorsCollected
+}
+
+// the edited src. Don't modify it, it is used by the next edits
+func (c *CstDoc) Bytes() []byte {
+	return c.doc.src
+}
+
+func (c *CstDoc) String() string {
+	return string(c.doc.src)
+}
+
+func (c *CstDoc) GetPath(keysMerged string) (JSON_value, error) {
+	return c.doc.GetPath(keysMerged)
+}
+
+// if autoCreateChildren == true, the missing objects of the path are created
+func (c *CstDoc) SetPath(keysMerged string, value JSON_value, autoCreateChildren bool) error { // TESTED
+	keys, err := cst_path_split(keysMerged)
+	if err != nil {
+		return err
+	}
+	return c.SetPathKeys(keys, value, autoCreateChildren)
+}
+
+func (c *CstDoc) SetPathKeys(keysEmbedded []string, value JSON_value, autoCreateChildren bool) error { // TESTED
+	if len(keysEmbedded) < 1 {
+		return errors.New(errorPrefix + "missing object keys (no keys are passed)")
+	}
+	d := c.doc
+	posNow := 0
+	for keyNum, key := range keysEmbedded {
+		var posNext int
+		var err error
+		tokenType := d.tokens[posNow].tokenType
+		if tokenType == '{' {
+			posNext, err = d.obj_value_find(posNow, key)
+			if err != nil && (autoCreateChildren || keyNum == len(keysEmbedded)-1) {
+				for posKey := len(keysEmbedded) - 1; posKey > keyNum; posKey-- { // the missing objects of the path
+					parent := NewObj()
+					parent.valObject_set(keysEmbedded[posKey], value)
+					value = parent
+				}
+				return c.obj_member_insert(posNow, key, value)
+			}
+		} else if tokenType == '[' {
+			posNext, err = d.arr_elem_find(posNow, key)
+		} else {
+			err = errors.New(errorPrefix + key + "-> parent is not object or array, key cannot be used")
+		}
+		if err != nil {
+			return err
+		}
+		posNow = posNext
+	}
+	return c.src_replace(d.tokens[posNow].posInSrcFirst, d.value_pos_after(posNow), cst_value_text(value))
+}
+
+func (c *CstDoc) DeletePath(keysMerged string) error { // TESTED
+	keys, err := cst_path_split(keysMerged)
+	if err != nil {
+		return err
+	}
+	return c.DeletePathKeys(keys)
+}
+
+// the member/elem is removed with one comma. The root can't be deleted
+func (c *CstDoc) DeletePathKeys(keysEmbedded []string) error { // TESTED
+	if len(keysEmbedded) < 1 {
+		return errors.New(errorPrefix + "missing object keys (no keys are passed)")
+	}
+	d := c.doc
+	posParent := 0
+	if len(keysEmbedded) > 1 {
+		var err error
+		if posParent, err = d.path_token_find(keysEmbedded[:len(keysEmbedded)-1]); err != nil {
+			return err
+		}
+	}
+	posValue, err := d.path_token_find(keysEmbedded)
+	if err != nil {
+		return err
+	}
+
+	posFirst := posValue // the first token of the member: the key in objects
+	if d.tokens[posParent].tokenType == '{' {
+		posFirst = posValue - 2
+	}
+	posNext := d.value_end_next(posValue) // comma or closer
+
+	if d.tokens[posNext].tokenType == ',' { // not the last member: removed till the next member
+		return c.src_replace(d.tokens[posFirst].posInSrcFirst, d.tokens[posNext+1].posInSrcFirst, "")
+	}
+	if d.tokens[posFirst-1].tokenType == ',' { // the last member: removed from the end of the previous member
+		return c.src_replace(d.tokens[posFirst-2].posInSrcLast+1, d.value_pos_after(posValue), "")
+	}
+	// the only member: the container is emptied
+	return c.src_replace(d.tokens[posParent].posInSrcLast+1, d.tokens[posNext].posInSrcFirst, "")
+}
+
+// the new member is inserted after the last member, with the separator of the previous member
+func (c *CstDoc) obj_member_insert(posObj int, key string, value JSON_value) error {
+	d := c.doc
+	posCloser := d.pairs[posObj]
+	if posCloser == posObj+1 { // empty object
+		memberText := NewStr(key).Repr() + d.colon_text(-1) + cst_value_text(value)
+		return c.src_replace(d.tokens[posObj].posInSrcLast+1, d.tokens[posObj].posInSrcLast+1, memberText)
+	}
+
+	posKeyLast := posObj + 1
+	for pos := posObj + 1; pos < posCloser; pos = d.value_end_next(pos+2) + 1 {
+		posKeyLast = pos
+	}
+	// the text before the last key: after the comma, or after the opener
+	separator := string(d.src[d.tokens[posKeyLast-1].posInSrcLast+1 : d.tokens[posKeyLast].posInSrcFirst])
+	if posKeyLast == posObj+1 && !strings.Contains(separator, "\n") {
+		separator = " " // one member, in one line
+	}
+	memberText := NewStr(key).Repr() + d.colon_text(posKeyLast+1) + cst_value_text(value)
+	posInsert := d.value_pos_after(posKeyLast + 2)
+	return c.src_replace(posInsert, posInsert, ","+separator+memberText)
+}
+
+// the src section is replaced, and the new src is tokenized again
+func (c *CstDoc) src_replace(posFirst, posAfter int, text string) error {
+	src := make([]byte, 0, len(c.doc.src)-(posAfter-posFirst)+len(text))
+	src = append(src, c.doc.src[:posFirst]...)
+	src = append(src, text...)
+	src = append(src, c.doc.src[posAfter:]...)
+	doc, errorsCollected := LazyParseBytes(src)
+	if len(errorsCollected) > 0 {
+		return errorsCollected[0] // the src is not modified
+	}
+	c.doc = doc
+	return nil
+}
+
+// the byte position after the last char of the value
+func (d LazyDoc) value_pos_after(posValue int) int {
+	return d.tokens[d.value_end_next(posValue)-1].posInSrcLast + 1
+}
+
+// the text between a key and its value, around the colon token.
+// posColon -1: the first colon of the src is used
+func (d LazyDoc) colon_text(posColon int) string {
+	for pos := 0; posColon < 0 && pos < len(d.tokens); pos++ {
+		if d.tokens[pos].tokenType == ':' {
+			posColon = pos
+		}
+	}
+	if posColon < 0 {
+		return ": "
+	}
+	return string(d.src[d.tokens[posColon-1].posInSrcLast+1 : d.tokens[posColon+1].posInSrcFirst])
+}
+
+func cst_path_split(keysMerged string) ([]string, error) {
+	if len(keysMerged) < 2 {
+		return nil, errors.New(errorPrefix + "missing separator and key(s) in merged path")
+	}
+	return ObjPath_merged_expand__split_with_first_char(keysMerged)
+}
+
+// the new value in one line, the keys in insertion order
+func cst_value_text(value JSON_value) string { // TESTED
+	return value.ReprWithOptions(ReprOptions{KeysInsertionOrder: true})
+}
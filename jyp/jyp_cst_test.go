@@ -0,0 +1,134 @@
+/*
+Copyright (c) 2024, Balazs Nyiro, balazs.nyiro.ca@gmail.com
+All rights reserved.
+
+This source code (all file in this repo) is licensed
+under the Apache-2 style license found in the
+LICENSE file in the root directory of this source tree.
+
+*/
+
+package jyp
+
+import (
+	"testing"
+)
+
+// go test -v -run Test_CstDoc_SetPath
+func Test_CstDoc_SetPath(t *testing.T) {
+	funName := "Test_CstDoc_SetPath"
+	src := `{
+    "name":  "web",
+    "price": 1.50,
+    "limit": 1e3,
+    "tags":  [ "a", "b" ],
+    "spec": {
+        "replicas": 2
+    }
+}
+`
+	testName := funName + "_replace" // only the value is rewritten
+	doc, errorsCollected := CstParse(src)
+	compare_int_int(testName, 0, len(errorsCollected), t)
+	compare_bool_bool(testName, true, doc.SetPath("/spec/replicas", NewNumInt(3), false) == nil, t)
+	compare_bool_bool(testName, true, doc.SetPath("/tags/1", NewStr("c"), false) == nil, t)
+	compare_str_str(testName, `{
+    "name":  "web",
+    "price": 1.50,
+    "limit": 1e3,
+    "tags":  [ "a", "c" ],
+    "spec": {
+        "replicas": 3
+    }
+}
+`, doc.String(), t)
+
+	testName = funName + "_insert" // with the indentation of the previous member
+	compare_bool_bool(testName, true, doc.SetPath("/spec/image", NewStr("nginx"), false) == nil, t)
+	compare_bool_bool(testName, true, doc.SetPath("/meta/labels/app", NewStr("web"), true) == nil, t)
+	compare_str_str(testName, `{
+    "name":  "web",
+    "price": 1.50,
+    "limit": 1e3,
+    "tags":  [ "a", "c" ],
+    "spec": {
+        "replicas": 3,
+        "image": "nginx"
+    },
+    "meta": {"labels":{"app":"web"}}
+}
+`, doc.String(), t)
+	value, _ := doc.GetPath("/meta/labels/app")
+	compare_str_str(testName, "web", value.ValRunes, t)
+
+	testName = funName + "_insert_in_one_line"
+	srcInserted := map[string]string{
+		`{}`:               `{"a": 1}`,
+		`{"b":2}`:          `{"b":2, "a":1}`,
+		`{"b": 2, "c": 3}`: `{"b": 2, "c": 3, "a": 1}`,
+		`{"b":2,"c":3}`:    `{"b":2,"c":3,"a":1}`,
+	}
+	for srcNow, srcWanted := range srcInserted {
+		doc, _ = CstParse(srcNow)
+		compare_bool_bool(testName, true, doc.SetPath("/a", NewNumInt(1), false) == nil, t)
+		compare_str_str(testName, srcWanted, doc.String(), t)
+	}
+
+	testName = funName + "_errors" // the src is not modified
+	doc, _ = CstParse(`{"a": [1, 2], "b": 3}`)
+	compare_bool_bool(testName, true, doc.SetPath("/x/y", NewNumInt(1), false) != nil, t)
+	compare_bool_bool(testName, true, doc.SetPath("/a/5", NewNumInt(1), false) != nil, t)
+	compare_bool_bool(testName, true, doc.SetPath("/b/c", NewNumInt(1), true) != nil, t)
+	compare_bool_bool(testName, true, doc.SetPath("/", NewNumInt(1), false) != nil, t)
+	compare_str_str(testName, `{"a": [1, 2], "b": 3}`, doc.String(), t)
+
+	testName = funName + "_src_is_copied"
+	srcBytes := []byte(`[1]`)
+	doc, _ = CstParseBytes(srcBytes)
+	_ = doc.SetPath("/0", NewNumInt(2), false)
+	compare_str_str(testName, `[1]`, string(srcBytes), t)
+	compare_str_str(testName, `[2]`, doc.String(), t)
+
+	testName = funName + "_invalid_src"
+	doc, errorsCollected = CstParse(`{"a": }`)
+	compare_bool_bool(testName, true, doc == nil && len(errorsCollected) > 0, t)
+}
+
+// go test -v -run Test_CstDoc_DeletePath
+func Test_CstDoc_DeletePath(t *testing.T) {
+	funName := "Test_CstDoc_DeletePath"
+
+	testName := funName + "_members"
+	src := `{
+  "a": 1,
+  "b": [10, 20, 30],
+  "c": {"d": 1e3}
+}`
+	doc, _ := CstParse(src)
+	compare_bool_bool(testName, true, doc.DeletePath("/a") == nil, t) // first
+	compare_bool_bool(testName, true, doc.DeletePath("/b/1") == nil, t)
+	compare_bool_bool(testName, true, doc.DeletePath("/b/1") == nil, t) // last
+	compare_bool_bool(testName, true, doc.DeletePath("/c/d") == nil, t) // only
+	compare_str_str(testName, `{
+  "b": [10],
+  "c": {}
+}`, doc.String(), t)
+	compare_bool_bool(testName, true, doc.DeletePath("/c") == nil, t)
+	compare_str_str(testName, `{
+  "b": [10]
+}`, doc.String(), t)
+
+	testName = funName + "_errors"
+	compare_bool_bool(testName, true, doc.DeletePath("/x") != nil, t)
+	compare_bool_bool(testName, true, doc.DeletePath("/b/3") != nil, t)
+	compare_bool_bool(testName, true, doc.DeletePathKeys([]string{}) != nil, t)
+}
+
+// go test -v -run Test_cst_value_text
+func Test_cst_value_text(t *testing.T) {
+	testName := "Test_cst_value_text"
+	obj := NewObj()
+	_ = obj.AddKeyVal("z", NewNumInt(1))
+	_ = obj.AddKeyVal("a", NewArr(NewBool(true), NewNull()))
+	compare_str_str(testName, `{"z":1,"a":[true,null]}`, cst_value_text(obj), t)
+}
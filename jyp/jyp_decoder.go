@@ -0,0 +1,390 @@
+/*
+Copyright (c) 2024, Balazs Nyiro, balazs.nyiro.ca@gmail.com
+All rights reserved.
+
+This source code (all file in this repo) is licensed
+under the Apache-2 style license found in the
+LICENSE file in the root directory of this source tree.
+
+This module: streaming decoder, JSON values are read from an io.Reader.
+
+Decode builds the value directly from the token stream of the reader (see Token()):
+only the actual string or number is buffered, there is no copy of the src
+and no token table, the memory use is the tree of the value.
+With Options.Recover the bytes of the value are collected first, and the
+value is parsed as a whole: the broken parts are recovered from the complete src.
+More values can be read from the same stream, one after the other:
+
+	decoder := jyp.NewDecoder(os.Stdin)
+	for decoder.More() {
+		value, errorsCollected := decoder.Decode()
+		...
+	}
+
+The tree of a root value is in the memory completely. A huge array can be
+opened with Token(), then its elems are decoded one by one, so only one elem is in the memory:
+
+	decoder.Token() // [
+	for decoder.More() {
+		elem, errorsCollected := decoder.Decode()
+		...
+	}
+*/
+
+package jyp
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+type Decoder struct {
+	reader   *bufio.Reader
+	valueBuf []byte  // the bytes of the actual root value. the buffer is reused between values
+	opts     Options // see SetOptions, SetLimits
+
+	// state of the Token() api, see jyp_decoder_token.go
+	tokenContainers []byte // the open '{' and '[' containers
+	tokenChildren   []int  // num of elems/keys in the open containers, for the limits
+	tokenWanted     rune   // the wanted next token, the same codes are used as in stepB
+	tokenErr        error  // after a syntax error the token stream is stopped
+
+	// the position of the next unread byte in the stream
+	posRune int
+	posByte int
+	line    int // 1 based
+	column  int // 1 based, counted in runes
+}
+
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{
+		reader:      bufio.NewReader(r),
+		line:        1,
+		column:      1,
+		tokenWanted: 'v',
+	}
+}
+
+// the limits are checked while the stream is read, so a too big or too deep value
+// is rejected before it is buffered. MaxInputBytes is the size of one value here.
+// After an exceeded limit the stream cannot be continued
+func (d *Decoder) SetLimits(limits Limits) {
+	d.opts.Limits = limits
+}
+
+// the options of the value parsing in Decode: DuplicateKeys, NumbersRaw, Recover, Positions...
+// opts.Limits is used as in SetLimits. Relaxed and Comments are not used: the end of a value
+// is found in the stream with the standard JSON syntax. The Positions are counted from the start of the value
+func (d *Decoder) SetOptions(opts Options) {
+	opts.Relaxed, opts.Comments = false, false
+	d.opts = opts
+}
+
+// More reports whether there is another value in the stream,
+// or in the actual array/object, if the Token() api is used. Whitespaces are skipped
+func (d *Decoder) More() bool {
+	d.whitespaces_skip()
+	nextByte, err := d.reader.Peek(1)
+	return err == nil && nextByte[0] != ']' && nextByte[0] != '}'
+}
+
+// Decode reads the next complete root value from the stream.
+// If there is no more value, errorsCollected is: []error{io.EOF}
+// ParseError positions are counted from the beginning of the stream.
+// After a syntax error the stream cannot be continued, as in Token().
+//
+// Decode can be mixed with Token() calls: inside an array or after an object key
+// the next value is decoded.
+func (d *Decoder) Decode() (JSON_value, []error) {
+	if err := d.token_separator_before_value(); err != nil {
+		return JSON_value{}, []error{err}
+	}
+	if d.opts.Recover {
+		return d.decode_buffered()
+	}
+	return d.decode_from_tokens()
+}
+
+// an open container of decode_from_tokens
+type decoderContainer struct {
+	elem       JSON_value
+	posStart   SrcPosition // Options.Positions: the position of the opener
+	objKey     string      // the key of the actual child in an object
+	objKeySpan SrcSpan     // Options.Positions: the span of the key of the actual child
+
+	objKeysCollected map[string]bool        // DuplicateKeysCollect: the keys where the values are collected already
+	objKeysFirst     map[string]SrcPosition // DuplicateKeysError: the position of the keys
+}
+
+// the value is built from Token() calls, without recursion, as in stepC
+func (d *Decoder) decode_from_tokens() (JSON_value, []error) {
+	if nextByte, err := d.reader.Peek(1); err == nil && (nextByte[0] == '}' || nextByte[0] == ']') {
+		return JSON_value{}, []error{d.token_error(ErrUnexpectedToken, "a value is not expected here", rune(nextByte[0]))}
+	}
+	posValueStart := d.position()
+	errorsCollected := []error{} // the not syntax errors (duplicated keys): the value is read till its end
+	containers := []decoderContainer{}
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return JSON_value{}, []error{err}
+		}
+		if limit_is_exceeded(d.opts.Limits.MaxInputBytes, d.posByte-posValueStart.ByteOffset) {
+			return JSON_value{}, []error{d.token_error(ErrLimitExceeded, limit_exceeded_msg("MaxInputBytes", d.opts.Limits.MaxInputBytes), token.TokenType)}
+		}
+		tokenStart := SrcPosition{Offset: token.Offset, ByteOffset: token.ByteOffset, Line: token.Line, Column: token.Column}
+		tokenSpan := SrcSpan{Start: decoder_position_in_value(posValueStart, tokenStart), End: decoder_position_in_value(posValueStart, d.position())}
+
+		var elem JSON_value
+		switch token.TokenType {
+		case 'k':
+			parent := &containers[len(containers)-1]
+			parent.objKey = token.Key
+			parent.objKeySpan = tokenSpan
+			if err := parent.key_duplication_check(token, d.opts.DuplicateKeys); err != nil {
+				errorsCollected = append(errorsCollected, err)
+			}
+			continue
+		case '{', '[':
+			container := decoderContainer{elem: NewArr(), posStart: tokenSpan.Start}
+			if token.TokenType == '{' {
+				container.elem = NewObj()
+			}
+			containers = append(containers, container)
+			continue
+		case '}', ']':
+			elem = containers[len(containers)-1].elem
+			if d.opts.Positions {
+				elem.Pos = &SrcSpans{Value: SrcSpan{Start: containers[len(containers)-1].posStart, End: tokenSpan.End}}
+			}
+			containers = containers[:len(containers)-1]
+		default: // strings, numbers, true, false, null
+			elem = token.Value // with NumbersRaw, the literal is in the token value
+			if d.opts.Positions {
+				elem.Pos = &SrcSpans{Value: tokenSpan}
+			}
+		}
+
+		// the elem is complete: it is the result, or a child of the actual container
+		if len(containers) == 0 {
+			if len(errorsCollected) > 0 {
+				return JSON_value{}, errorsCollected
+			}
+			return elem, errorsCollected
+		}
+		parent := &containers[len(containers)-1]
+		if parent.elem.ValType == '{' {
+			if d.opts.Positions {
+				elem.Pos.Key = parent.objKeySpan
+			}
+			parent.objKeysCollected = objValue_add_L2(parent.elem, parent.objKey, elem, d.opts.DuplicateKeys, parent.objKeysCollected)
+		} else {
+			parent.elem.ValArray = append(parent.elem.ValArray, elem)
+		}
+	}
+}
+
+// DuplicateKeysError: the error has the position of both keys, as in stepB
+func (c *decoderContainer) key_duplication_check(token Token, policy DuplicateKeyPolicy) error {
+	if policy != DuplicateKeysError {
+		return nil
+	}
+	if c.objKeysFirst == nil {
+		c.objKeysFirst = map[string]SrcPosition{}
+	}
+	posFirst, isUsed := c.objKeysFirst[token.Key]
+	if !isUsed {
+		c.objKeysFirst[token.Key] = SrcPosition{Offset: token.Offset, ByteOffset: token.ByteOffset, Line: token.Line, Column: token.Column}
+		return nil
+	}
+	return ParseError{
+		Kind:       ErrDuplicateKey,
+		Msg:        fmt.Sprintf("duplicate object key %q, first used at line %d, column %d", token.Key, posFirst.Line, posFirst.Column),
+		Offset:     token.Offset,
+		ByteOffset: token.ByteOffset,
+		Line:       token.Line,
+		Column:     token.Column,
+		TokenType:  '"',
+	}
+}
+
+// Options.Recover: the bytes of the value are collected, and parsed as a whole
+func (d *Decoder) decode_buffered() (JSON_value, []error) {
+	posRuneStart, posByteStart, lineStart, columnStart := d.posRune, d.posByte, d.line, d.column
+
+	if err := d.token_child_add(); err != nil {
+		return JSON_value{}, []error{err}
+	}
+	if _, err := d.valueBytes_read(); err != nil {
+		return JSON_value{}, []error{err}
+	}
+
+	value, errorsCollected := JsonParseBytesWithOptions(d.valueBuf, d.opts)
+	for pos, err := range errorsCollected {
+		if parseErr, isParseErr := err.(ParseError); isParseErr {
+			errorsCollected[pos] = parseErr.shifted(posRuneStart, posByteStart, lineStart, columnStart)
+		}
+	}
+	d.token_after_value()
+	return value, errorsCollected
+}
+
+// the position of the next unread byte in the stream
+func (d *Decoder) position() SrcPosition {
+	return SrcPosition{Offset: d.posRune, ByteOffset: d.posByte, Line: d.line, Column: d.column}
+}
+
+// Options.Positions in Decode are counted from the start of the value, as if the value were the src
+func decoder_position_in_value(posValueStart, posInStream SrcPosition) SrcPosition { // TESTED
+	posInValue := SrcPosition{
+		Offset:     posInStream.Offset - posValueStart.Offset,
+		ByteOffset: posInStream.ByteOffset - posValueStart.ByteOffset,
+		Line:       posInStream.Line - posValueStart.Line + 1,
+		Column:     posInStream.Column,
+	}
+	if posInStream.Line == posValueStart.Line {
+		posInValue.Column = posInStream.Column - posValueStart.Column + 1
+	}
+	return posInValue
+}
+
+// read the bytes of the next value into d.valueBuf.
+// an incomplete value can be read if the stream ends: the parser reports the unclosed elems
+func (d *Decoder) valueBytes_read() (decoderValueScan, error) {
+	d.valueBuf = d.valueBuf[:0]
+	scan := decoderValueScan{depthLimit: d.opts.Limits.MaxDepth, depthOuter: len(d.tokenContainers)}
+	for !scan.complete {
+		if _, err := d.reader.Peek(1); err != nil {
+			if err != io.EOF {
+				return scan, err
+			}
+			break
+		}
+		buffered, _ := d.reader.Peek(d.reader.Buffered())
+		numOfBytesUsed := scan.bytes_process(buffered)
+
+		d.valueBuf = append(d.valueBuf, buffered[:numOfBytesUsed]...)
+		d.position_move(buffered[:numOfBytesUsed])
+		d.reader.Discard(numOfBytesUsed)
+
+		if limit_is_exceeded(d.opts.Limits.MaxInputBytes, len(d.valueBuf)) {
+			return scan, d.token_error(ErrLimitExceeded, limit_exceeded_msg("MaxInputBytes", d.opts.Limits.MaxInputBytes), '?')
+		}
+		if scan.depthExceeded { // the stream position is at the opener that is too deep
+			return scan, d.token_error(ErrLimitExceeded, limit_exceeded_msg("MaxDepth", d.opts.Limits.MaxDepth), '?')
+		}
+	}
+	return scan, nil
+}
+
+func (d *Decoder) whitespaces_skip() {
+	for {
+		buffered, err := d.reader.Peek(1)
+		if err != nil {
+			return
+		}
+		buffered, _ = d.reader.Peek(d.reader.Buffered())
+		numOfWhitespaces := 0
+		for numOfWhitespaces < len(buffered) && base__is_whitespace_byte(buffered[numOfWhitespaces]) {
+			numOfWhitespaces++
+		}
+		d.position_move(buffered[:numOfWhitespaces])
+		d.reader.Discard(numOfWhitespaces)
+		if numOfWhitespaces < len(buffered) {
+			return // a non-whitespace byte is found
+		}
+	}
+}
+
+// follow the stream position, for the error messages
+func (d *Decoder) position_move(bytesUsed []byte) {
+	d.posByte += len(bytesUsed)
+	for _, b := range bytesUsed {
+		if b&0xC0 == 0x80 {
+			continue // utf8 continuation byte, it is not a new rune
+		}
+		d.posRune++
+		if b == '\n' {
+			d.line++
+			d.column = 1
+		} else {
+			d.column++
+		}
+	}
+}
+
+// the end of a root value is detected with this, without tokenizing.
+type decoderValueScan struct {
+	valueKind byte // 0: not started yet, '{': object or array, '"': string, '0': number or other literal
+	depth     int
+	inString  bool
+	isEscaped bool
+	complete  bool
+
+	depthLimit    int  // Limits.MaxDepth, 0: no limit
+	depthOuter    int  // the open containers of the Token() api, around the value
+	depthExceeded bool // the scan is stopped before the opener that exceeds the limit
+}
+
+// return with the number of bytes that belong to the actual value
+func (scan *decoderValueScan) bytes_process(buffered []byte) int {
+	for pos, b := range buffered {
+		if scan.valueKind == 0 {
+			if b == '{' || b == '[' {
+				scan.valueKind = '{'
+			} else if b == '"' {
+				scan.valueKind = '"'
+				scan.inString = true
+				continue
+			} else if b == '}' || b == ']' || b == ',' || b == ':' {
+				scan.complete = true // a lonely structural char, the parser will report it
+				return pos + 1
+			} else {
+				scan.valueKind = '0'
+			}
+		}
+
+		if scan.inString {
+			if scan.isEscaped {
+				scan.isEscaped = false
+			} else if b == '\\' {
+				scan.isEscaped = true
+			} else if b == '"' {
+				scan.inString = false
+				if scan.valueKind == '"' {
+					scan.complete = true
+					return pos + 1
+				}
+			}
+			continue
+		}
+
+		if scan.valueKind == '0' {
+			// the delimiter is not part of the literal, it stays in the stream
+			if base__is_whitespace_byte(b) || b == '{' || b == '}' || b == '[' || b == ']' || b == ',' || b == ':' || b == '"' {
+				scan.complete = true
+				return pos
+			}
+			continue
+		}
+
+		// object or array
+		if b == '"' {
+			scan.inString = true
+		} else if b == '{' || b == '[' {
+			if limit_is_exceeded(scan.depthLimit, scan.depthOuter+scan.depth+1) {
+				scan.depthExceeded = true
+				return pos
+			}
+			scan.depth++
+		} else if b == '}' || b == ']' {
+			scan.depth--
+			if scan.depth == 0 {
+				scan.complete = true
+				return pos + 1
+			}
+		}
+	}
+	return len(buffered)
+}
@@ -0,0 +1,141 @@
+/*
+Copyright (c) 2024, Balazs Nyiro, balazs.nyiro.ca@gmail.com
+All rights reserved.
+
+This source code (all file in this repo) is licensed
+under the Apache-2 style license found in the
+LICENSE file in the root directory of this source tree.
+
+*/
+
+package jyp
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// go test -v -run Test_Decoder_more_values
+func Test_Decoder_more_values(t *testing.T) {
+	funName := "Test_Decoder_more_values"
+	testName := funName + "_basic"
+
+	src := ` {"a": "}{", "b": [1, 2]} [3, "\"]"]
+"str\"" 42 true null{"c":{}}`
+	// one byte reader: the values are split between many reads
+	decoder := NewDecoder(iotest.OneByteReader(strings.NewReader(src)))
+
+	// Repr() doesn't escape the strings, so "]" is printed simply
+	reprsWanted := []string{`{"a":"}{","b":[1,2]}`, `[3,"\"]"]`, `"str\""`, `42`, `true`, `null`, `{"c":{}}`}
+	for _, reprWanted := range reprsWanted {
+		compare_bool_bool(testName, true, decoder.More(), t)
+		value, errorsCollected := decoder.Decode()
+		compare_int_int(testName, 0, len(errorsCollected), t)
+		compare_str_str(testName, reprWanted, value.Repr(), t)
+	}
+	compare_bool_bool(testName, false, decoder.More(), t)
+
+	testName = funName + "_eof"
+	_, errorsCollected := decoder.Decode()
+	compare_int_int(testName, 1, len(errorsCollected), t)
+	compare_bool_bool(testName, true, errors.Is(errorsCollected[0], io.EOF), t)
+}
+
+// go test -v -run Test_Decoder_errors
+func Test_Decoder_errors(t *testing.T) {
+	funName := "Test_Decoder_errors"
+	testName := funName + "_position_in_stream"
+
+	decoder := NewDecoder(strings.NewReader("[1]\n  {\"a\" 1}"))
+	_, errorsCollected := decoder.Decode()
+	compare_int_int(testName, 0, len(errorsCollected), t)
+
+	_, errorsCollected = decoder.Decode()
+	compare_int_int(testName, 1, len(errorsCollected), t)
+	var parseErr ParseError
+	compare_bool_bool(testName, true, errors.As(errorsCollected[0], &parseErr), t)
+	compare_bool_bool(testName, true, errors.Is(parseErr, ErrMissingColon), t)
+	compare_int_int(testName, 2, parseErr.Line, t)
+	compare_int_int(testName, 8, parseErr.Column, t)
+	compare_int_int(testName, 11, parseErr.Offset, t)
+
+	testName = funName + "_excerpt_in_first_line" // the value doesn't start the line: no misaligned caret
+	decoder = NewDecoder(strings.NewReader(`[1] {"a" 1}`))
+	_, _ = decoder.Decode()
+	_, errorsCollected = decoder.Decode()
+	compare_int_int(testName, 10, errorsCollected[0].(ParseError).Column, t)
+	compare_bool_bool(testName, false, strings.Contains(errorsCollected[0].Error(), "\n"), t)
+
+	testName = funName + "_options" // the options of the parsing are used in Decode
+	decoder = NewDecoder(strings.NewReader("[1]\n{\"a\": 1, \"a\": 2}"))
+	decoder.SetOptions(Options{DuplicateKeys: DuplicateKeysError, Relaxed: true})
+	_, errorsCollected = decoder.Decode()
+	compare_int_int(testName, 0, len(errorsCollected), t)
+	_, errorsCollected = decoder.Decode()
+	compare_bool_bool(testName, true, errors.Is(errorsCollected[0], ErrDuplicateKey), t)
+	compare_int_int(testName, 2, errorsCollected[0].(ParseError).Line, t)
+	compare_int_int(testName, 10, errorsCollected[0].(ParseError).Column, t)
+
+	testName = funName + "_not_json_whitespace"
+	for _, src := range []string{"\vnull", "[1,\f2]"} {
+		_, errorsCollected = NewDecoder(strings.NewReader(src)).Decode()
+		compare_bool_bool(testName, true, errors.Is(errorsCollected[0], ErrInvalidLiteral), t)
+	}
+
+	testName = funName + "_unclosed_at_end"
+	decoder = NewDecoder(strings.NewReader(`{"a": [1, 2`))
+	_, errorsCollected = decoder.Decode()
+	compare_bool_bool(testName, true, errors.Is(errorsCollected[0], ErrUnclosedContainer), t)
+}
+
+// go test -v -run Test_Decoder_from_tokens
+func Test_Decoder_from_tokens(t *testing.T) {
+	funName := "Test_Decoder_from_tokens"
+
+	testName := funName + "_not_buffered" // only the actual scalar is in valueBuf, not the whole value
+	src := "[" + strings.Repeat(`{"id": 12345, "name": "abc"}, `, 10000) + `"last"]`
+	decoder := NewDecoder(strings.NewReader(src))
+	value, errorsCollected := decoder.Decode()
+	compare_int_int(testName, 0, len(errorsCollected), t)
+	compare_int_int(testName, 10001, len(value.ValArray), t)
+	compare_bool_bool(testName, true, cap(decoder.valueBuf) < 100, t)
+
+	testName = funName + "_options"
+	decoder = NewDecoder(strings.NewReader("{\"a\": [1.50, 1e3],\n \"a\": {\"b\": null}, \"c\": 2}"))
+	decoder.SetOptions(Options{NumbersRaw: true, Positions: true, DuplicateKeys: DuplicateKeysCollect})
+	value, errorsCollected = decoder.Decode()
+	compare_int_int(testName, 0, len(errorsCollected), t)
+	compare_str_str(testName, `{"a":[[1.50,1e3],{"b":null}],"c":2}`, value.ReprWithOptions(ReprOptions{KeysInsertionOrder: true}), t)
+	spans, _ := value.PosGetPath("/a/1/b")
+	compare_int_int(testName, 2, spans.Value.Start.Line, t)
+	compare_int_int(testName, 13, spans.Value.Start.Column, t)
+	compare_int_int(testName, 2, spans.Key.Start.Line, t)
+	compare_int_int(testName, 8, spans.Key.Start.Column, t)
+	spans, _ = value.PosGetPath("/c")
+	compare_int_int(testName, 2, spans.Value.End.Line, t)
+	compare_int_int(testName, 26, spans.Value.End.Column, t)
+
+	testName = funName + "_positions_in_value" // counted from the start of the value
+	compare_int_int(testName, 1, decoder_position_in_value(SrcPosition{Line: 3, Column: 5}, SrcPosition{Line: 3, Column: 5}).Column, t)
+	compare_int_int(testName, 7, decoder_position_in_value(SrcPosition{Line: 3, Column: 5}, SrcPosition{Line: 4, Column: 7}).Column, t)
+	compare_int_int(testName, 2, decoder_position_in_value(SrcPosition{Line: 3, Column: 5}, SrcPosition{Line: 4, Column: 7}).Line, t)
+
+	testName = funName + "_closer_is_not_a_value"
+	decoder = NewDecoder(strings.NewReader(`[]`))
+	_, _ = decoder.Token()
+	_, errorsCollected = decoder.Decode()
+	compare_bool_bool(testName, true, errors.Is(errorsCollected[0], ErrUnexpectedToken), t)
+
+	testName = funName + "_recover" // the value is buffered, and recovered as a whole
+	decoder = NewDecoder(strings.NewReader(`{"a": 1 "b": 2} [3]`))
+	decoder.SetOptions(Options{Recover: true})
+	value, errorsCollected = decoder.Decode()
+	compare_bool_bool(testName, true, errors.Is(errorsCollected[0], ErrMissingComma), t)
+	compare_str_str(testName, `{"a":1,"b":2}`, value.Repr(), t)
+	value, errorsCollected = decoder.Decode()
+	compare_int_int(testName, 0, len(errorsCollected), t)
+	compare_str_str(testName, `[3]`, value.Repr(), t)
+}
@@ -0,0 +1,339 @@
+/*
+Copyright (c) 2024, Balazs Nyiro, balazs.nyiro.ca@gmail.com
+All rights reserved.
+
+This source code (all file in this repo) is licensed
+under the Apache-2 style license found in the
+LICENSE file in the root directory of this source tree.
+
+This module: pull-style token api of the Decoder.
+
+The stream is tokenized incrementally, no JSON_value tree is built:
+
+	decoder := jyp.NewDecoder(file)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF { break }
+		...
+	}
+
+commas and colons are checked, but they are not returned as tokens.
+Token(), Skip() and Decode() calls can be mixed: for example a huge array
+can be opened with Token(), and the elems can be decoded one by one with Decode().
+*/
+
+package jyp
+
+import "io"
+
+type Token struct {
+	/* { } [ ]  containers
+	   k        object key, the key is in Key
+	   "        string
+	   0        number: Value.ValType is 'I' or 'F'
+	   b        bool
+	   n        null                                         */
+	TokenType rune
+
+	Key   string     // filled if TokenType == 'k'
+	Value JSON_value // filled for strings, numbers, bools and null
+
+	// the position of the first char of the token in the stream
+	Offset     int // rune position, 0 based
+	ByteOffset int // 0 based
+	Line       int // 1 based
+	Column     int // 1 based, counted in runes
+}
+
+// Token returns the next token from the stream.
+// At the end of the stream, the error is io.EOF
+func (d *Decoder) Token() (Token, error) {
+	if d.tokenErr != nil {
+		return Token{}, d.tokenErr
+	}
+
+	for { // commas and colons are consumed in this loop, they are not returned
+		d.whitespaces_skip()
+		token := Token{Offset: d.posRune, ByteOffset: d.posByte, Line: d.line, Column: d.column}
+
+		nextByte, err := d.reader.Peek(1)
+		if err != nil {
+			if err == io.EOF && len(d.tokenContainers) > 0 {
+				return Token{}, d.token_error(ErrUnclosedContainer, "unclosed container at the end of the stream", '?')
+			}
+			return Token{}, err
+		}
+		b := nextByte[0]
+		wanted := d.tokenWanted
+		containerLast := d.token_containerLast()
+
+		if b == ',' {
+			if wanted != ',' || containerLast == 0 {
+				return Token{}, d.token_error(ErrUnexpectedToken, "unexpected comma", ',')
+			}
+			d.bytes_consume(1)
+			if containerLast == '{' {
+				d.tokenWanted = 'k'
+			} else {
+				d.tokenWanted = 'v'
+			}
+			continue
+		}
+
+		if b == ':' {
+			if wanted != ':' {
+				return Token{}, d.token_error(ErrUnexpectedToken, "unexpected colon", ':')
+			}
+			d.bytes_consume(1)
+			d.tokenWanted = 'v'
+			continue
+		}
+
+		if b == '}' || b == ']' {
+			opener := byte('{')
+			if b == ']' {
+				opener = '['
+			}
+			if containerLast != opener {
+				return Token{}, d.token_error(ErrUnpairedCloser, "", rune(b))
+			}
+			if wanted == 'k' || (wanted == 'v' && opener == '[') {
+				return Token{}, d.token_error(ErrTrailingComma, "", rune(b))
+			}
+			if wanted == ':' {
+				return Token{}, d.token_error(ErrMissingColon, "", rune(b))
+			}
+			if wanted == 'v' {
+				return Token{}, d.token_error(ErrMissingValue, "missing value after colon", rune(b))
+			}
+			d.bytes_consume(1)
+			d.tokenContainers = d.tokenContainers[:len(d.tokenContainers)-1]
+			d.tokenChildren = d.tokenChildren[:len(d.tokenContainers)]
+			d.token_after_value()
+			token.TokenType = rune(b)
+			return token, nil
+		}
+
+		// a key or a value is next
+		posStart := d.position() // the errors inside the key/value are reported from here
+		if wanted == ',' {
+			return Token{}, d.token_error(ErrMissingComma, "", rune(b))
+		}
+		if wanted == ':' {
+			return Token{}, d.token_error(ErrMissingColon, "", rune(b))
+		}
+
+		if wanted == 'k' || wanted == 'K' {
+			if b != '"' {
+				return Token{}, d.token_error(ErrKeyNotString, "", rune(b))
+			}
+			if err := d.token_child_add(); err != nil {
+				return Token{}, err
+			}
+			scan, err := d.valueBytes_read()
+			if err != nil {
+				return Token{}, err
+			}
+			if err := d.token_string_check(scan, posStart); err != nil {
+				return Token{}, err
+			}
+			token.TokenType = 'k'
+			token.Key = stringValueParsing_rawToInterpretedCharacters_L2(d.valueBuf[1:len(d.valueBuf)-1])
+			d.tokenWanted = ':'
+			return token, nil
+		}
+
+		// value is wanted
+		if err := d.token_child_add(); err != nil {
+			return Token{}, err
+		}
+		if b == '{' || b == '[' {
+			if limit_is_exceeded(d.opts.Limits.MaxDepth, len(d.tokenContainers)+1) {
+				return Token{}, d.token_error(ErrLimitExceeded, limit_exceeded_msg("MaxDepth", d.opts.Limits.MaxDepth), rune(b))
+			}
+			d.bytes_consume(1)
+			d.tokenContainers = append(d.tokenContainers, b)
+			d.tokenChildren = append(d.tokenChildren, 0)
+			if b == '{' {
+				d.tokenWanted = 'K'
+			} else {
+				d.tokenWanted = 'V'
+			}
+			token.TokenType = rune(b)
+			return token, nil
+		}
+
+		scan, err := d.valueBytes_read()
+		if err != nil {
+			return Token{}, err
+		}
+		if b == '"' {
+			if err := d.token_string_check(scan, posStart); err != nil {
+				return Token{}, err
+			}
+			token.TokenType = '"'
+			token.Value = NewString__rawToInterpreted__QuotedBothEnd(d.valueBuf)
+		} else {
+			value, isValid := token_literal_to_value(string(d.valueBuf))
+			if d.opts.NumbersRaw && numberValueParsing_literal_is_json_L2(string(d.valueBuf)) { // 1e400 is kept, too
+				value, isValid = numberValueParsing_textToNumber_raw_L2(string(d.valueBuf))
+			}
+			if !isValid {
+				return Token{}, d.token_error_in_value(ErrInvalidLiteral, "invalid literal: "+string(d.valueBuf), 0, '?', posStart)
+			}
+			token.TokenType = value.ValType
+			if value.ValType == 'I' || value.ValType == 'F' {
+				token.TokenType = '0'
+			}
+			token.Value = value
+		}
+		d.token_after_value()
+		return token, nil
+	}
+}
+
+// Skip skips the next value, with the whole subtree if it is an object or an array.
+// If an object key is the next token, the key and its value are skipped.
+func (d *Decoder) Skip() error {
+	depth := 0
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+		if token.TokenType == 'k' {
+			continue // the value of the key is skipped, too
+		}
+		if token.TokenType == '{' || token.TokenType == '[' {
+			depth++
+		} else if token.TokenType == '}' || token.TokenType == ']' {
+			depth--
+		}
+		if depth < 0 {
+			return d.token_error(ErrUnexpectedToken, "nothing to skip, the container is closed", token.TokenType)
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+// true/false/null or a number
+func token_literal_to_value(textInSrc string) (JSON_value, bool) { // TESTED
+	if textInSrc == "true" {
+		return NewBool(true), true
+	}
+	if textInSrc == "false" {
+		return NewBool(false), true
+	}
+	if textInSrc == "null" {
+		return NewNull(), true
+	}
+	if !numberValueParsing_literal_is_json_L2(textInSrc) {
+		return JSON_value{}, false
+	}
+	return numberValueParsing_textToNumber_L2(textInSrc)
+}
+
+// Decode() can be called between Token() calls: the separator before the value is consumed here
+func (d *Decoder) token_separator_before_value() error {
+	if d.tokenErr != nil {
+		return d.tokenErr
+	}
+	d.whitespaces_skip()
+	nextByte, err := d.reader.Peek(1)
+	if err != nil {
+		return err
+	}
+
+	if (d.tokenWanted == ',' && nextByte[0] == ',') || (d.tokenWanted == ':' && nextByte[0] == ':') {
+		d.bytes_consume(1)
+		d.tokenWanted = 'v'
+		d.whitespaces_skip()
+	}
+	if d.tokenWanted != 'v' && d.tokenWanted != 'V' {
+		return d.token_error(ErrUnexpectedToken, "a value is not expected here", '?')
+	}
+	return nil
+}
+
+func (d *Decoder) token_after_value() {
+	if len(d.tokenContainers) == 0 {
+		d.tokenWanted = 'v' // a new root value can come in the stream
+	} else {
+		d.tokenWanted = ','
+	}
+}
+
+// a new value in an array, or a new key in an object: the length limits are checked
+func (d *Decoder) token_child_add() error {
+	containerLast := d.token_containerLast()
+	if containerLast == 0 || (containerLast == '{' && d.tokenWanted == 'v') { // an object value: its key is counted
+		return nil
+	}
+	d.tokenChildren[len(d.tokenChildren)-1]++
+	children := d.tokenChildren[len(d.tokenChildren)-1]
+	if containerLast == '[' && limit_is_exceeded(d.opts.Limits.MaxArrayLen, children) {
+		return d.token_error(ErrLimitExceeded, limit_exceeded_msg("MaxArrayLen", d.opts.Limits.MaxArrayLen), '?')
+	}
+	if containerLast == '{' && limit_is_exceeded(d.opts.Limits.MaxObjectMembers, children) {
+		return d.token_error(ErrLimitExceeded, limit_exceeded_msg("MaxObjectMembers", d.opts.Limits.MaxObjectMembers), '?')
+	}
+	return nil
+}
+
+// the quoted string is in d.valueBuf, the problems are checked as in stepB:
+// unclosed string, MaxStringBytes, invalid utf8, invalid escapes
+func (d *Decoder) token_string_check(scan decoderValueScan, posStart SrcPosition) error {
+	if scan.inString {
+		return d.token_error_in_value(ErrUnclosedString, "", 0, '"', posStart)
+	}
+	if limit_is_exceeded(d.opts.Limits.MaxStringBytes, len(d.valueBuf)-2) {
+		return d.token_error(ErrLimitExceeded, limit_exceeded_msg("MaxStringBytes", d.opts.Limits.MaxStringBytes), '"')
+	}
+	if posInvalid := base__utf8_invalid_pos_first(d.valueBuf); posInvalid != -1 {
+		return d.token_error_in_value(ErrInvalidUTF8, "", posInvalid, '"', posStart)
+	}
+	if posInvalid, kind := stringValueParsing_escapes_check_L2(d.valueBuf[1:len(d.valueBuf)-1], false); kind != nil {
+		return d.token_error_in_value(kind, "", posInvalid+1, '"', posStart)
+	}
+	return nil
+}
+
+// 0 means: there is no open container
+func (d *Decoder) token_containerLast() byte {
+	if len(d.tokenContainers) == 0 {
+		return 0
+	}
+	return d.tokenContainers[len(d.tokenContainers)-1]
+}
+
+func (d *Decoder) bytes_consume(numOfBytes int) {
+	buffered, _ := d.reader.Peek(numOfBytes)
+	d.position_move(buffered)
+	d.reader.Discard(len(buffered))
+}
+
+// the error is saved: the token stream cannot be continued after a syntax error
+// an error inside the value of d.valueBuf (the bad escape of a string, for example):
+// the position is counted from the start of the value, not from the actual stream position
+func (d *Decoder) token_error_in_value(kind error, msg string, posInValue int, tokenType rune, posStart SrcPosition) error {
+	d.tokenErr = newParseError(d.valueBuf, posInValue, tokenType, kind, msg).shifted(posStart.Offset, posStart.ByteOffset, posStart.Line, posStart.Column)
+	return d.tokenErr
+}
+
+func (d *Decoder) token_error(kind error, msg string, tokenType rune) error {
+	if msg == "" {
+		msg = kind.Error()
+	}
+	d.tokenErr = ParseError{
+		Kind:       kind,
+		Msg:        msg,
+		Offset:     d.posRune,
+		ByteOffset: d.posByte,
+		Line:       d.line,
+		Column:     d.column,
+		TokenType:  tokenType,
+	}
+	return d.tokenErr
+}
@@ -0,0 +1,167 @@
+/*
+Copyright (c) 2024, Balazs Nyiro, balazs.nyiro.ca@gmail.com
+All rights reserved.
+
+This source code (all file in this repo) is licensed
+under the Apache-2 style license found in the
+LICENSE file in the root directory of this source tree.
+
+*/
+
+package jyp
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// go test -v -run Test_Decoder_Token
+func Test_Decoder_Token(t *testing.T) {
+	funName := "Test_Decoder_Token"
+	testName := funName + "_all_types"
+
+	src := `{"a": [1, -2.5, "s\tr"],
+ "b": {"c": true, "d": null}, "e": false}`
+	decoder := NewDecoder(iotest.OneByteReader(strings.NewReader(src)))
+
+	typesWanted := []rune{'{', 'k', '[', '0', '0', '"', ']', 'k', '{', 'k', 'b', 'k', 'n', '}', 'k', 'b', '}'}
+	tokens := []Token{}
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		compare_bool_bool(testName, true, err == nil, t)
+		tokens = append(tokens, token)
+	}
+	compare_int_int(testName, len(typesWanted), len(tokens), t)
+	for pos, typeWanted := range typesWanted {
+		compare_rune_rune(testName, typeWanted, tokens[pos].TokenType, t)
+	}
+	compare_str_str(testName, "a", tokens[1].Key, t)
+	compare_int_int(testName, 1, tokens[3].Value.ValNumberInt, t)
+	compare_flt_flt(testName, -2.5, tokens[4].Value.ValNumberFloat, t)
+	compare_str_str(testName, "s\tr", tokens[5].Value.ValRunes, t)
+
+	testName = funName + "_positions"
+	compare_int_int(testName, 2, tokens[7].Line, t) // "b"
+	compare_int_int(testName, 2, tokens[7].Column, t)
+	compare_int_int(testName, 26, tokens[7].Offset, t)
+}
+
+// go test -v -run Test_Decoder_Token_mixed_with_Decode
+func Test_Decoder_Token_mixed_with_Decode(t *testing.T) {
+	funName := "Test_Decoder_Token_mixed_with_Decode"
+	testName := funName + "_array_stream"
+
+	decoder := NewDecoder(strings.NewReader(`{"skipped": {"x": [1, {}]}, "items": [{"id": 1}, {"id": 2}, {"id": 3}]}`))
+	token, _ := decoder.Token()
+	compare_rune_rune(testName, '{', token.TokenType, t)
+
+	token, _ = decoder.Token()
+	compare_str_str(testName, "skipped", token.Key, t)
+	compare_bool_bool(testName, true, decoder.Skip() == nil, t)
+
+	token, _ = decoder.Token()
+	compare_str_str(testName, "items", token.Key, t)
+	token, _ = decoder.Token()
+	compare_rune_rune(testName, '[', token.TokenType, t)
+
+	ids := []int{}
+	for decoder.More() {
+		item, errorsCollected := decoder.Decode()
+		compare_int_int(testName, 0, len(errorsCollected), t)
+		ids = append(ids, item.ValObject["id"].ValNumberInt)
+	}
+	compare_int_int(testName, 3, len(ids), t)
+	compare_int_int(testName, 3, ids[2], t)
+
+	token, _ = decoder.Token()
+	compare_rune_rune(testName, ']', token.TokenType, t)
+	token, _ = decoder.Token()
+	compare_rune_rune(testName, '}', token.TokenType, t)
+	_, err := decoder.Token()
+	compare_bool_bool(testName, true, err == io.EOF, t)
+}
+
+// go test -v -run Test_Decoder_Token_errors
+func Test_Decoder_Token_errors(t *testing.T) {
+	funName := "Test_Decoder_Token_errors"
+
+	type srcWithError struct {
+		src     string
+		errKind error
+	}
+	for _, srcInvalid := range []srcWithError{
+		{`[1 2]`, ErrMissingComma},
+		{`{"a" 1}`, ErrMissingColon},
+		{`{"a": 1,}`, ErrTrailingComma},
+		{`{1: 2}`, ErrKeyNotString},
+		{`[tru]`, ErrInvalidLiteral},
+		{`["abc`, ErrUnclosedString},
+		{`[1, 2`, ErrUnclosedContainer},
+		{`[1}`, ErrUnpairedCloser},
+		{`["a\x"]`, ErrInvalidEscape},
+		{"{\"a\tb\": 1}", ErrControlCharacter},
+		{"[\"a\xffb\"]", ErrInvalidUTF8},
+		{"{\"\xe9\": 1}", ErrInvalidUTF8},
+	} {
+		testName := funName + ": " + srcInvalid.src
+		decoder := NewDecoder(strings.NewReader(srcInvalid.src))
+		var err error
+		for err == nil {
+			_, err = decoder.Token()
+		}
+		compare_bool_bool(testName, true, errors.Is(err, srcInvalid.errKind), t)
+
+		_, errAgain := decoder.Token() // the stream is stopped after an error
+		compare_bool_bool(testName, true, errors.Is(errAgain, srcInvalid.errKind), t)
+	}
+
+	testName := funName + "_escape_position" // the bad escape, not the end of the string
+	decoder := NewDecoder(strings.NewReader("[\"ab\",\n \"cd\\x\", 1]"))
+	var err error
+	for err == nil {
+		_, err = decoder.Token()
+	}
+	var parseErr ParseError
+	compare_bool_bool(testName, true, errors.As(err, &parseErr) && errors.Is(err, ErrInvalidEscape), t)
+	compare_int_int(testName, 2, parseErr.Line, t)
+	compare_int_int(testName, 5, parseErr.Column, t)
+	compare_int_int(testName, 11, parseErr.Offset, t)
+
+	testName = funName + "_start_position" // the start of the token, as in Decode, not the end of the read bytes
+	srcColumns := map[string]int{`"abc`: 1, `tru`: 1, ` [1, nul]`: 6, `{"ab`: 2, "[\"\xe9t\xe9\"]": 3}
+	for src, columnWanted := range srcColumns {
+		decoder = NewDecoder(strings.NewReader(src))
+		for err = nil; err == nil; {
+			_, err = decoder.Token()
+		}
+		compare_int_int(testName+" "+src, columnWanted, err.(ParseError).Column, t)
+		_, errorsCollected := NewDecoder(strings.NewReader(src)).Decode()
+		compare_int_int(testName+" "+src, columnWanted, errorsCollected[0].(ParseError).Column, t)
+	}
+}
+
+// go test -v -run Test_token_literal_to_value
+func Test_token_literal_to_value(t *testing.T) {
+	funName := "Test_token_literal_to_value"
+	testName := funName + "_base"
+
+	value, isValid := token_literal_to_value("null")
+	compare_bool_bool(testName, true, isValid, t)
+	compare_rune_rune(testName, 'n', value.ValType, t)
+
+	value, isValid = token_literal_to_value("-7")
+	compare_bool_bool(testName, true, isValid, t)
+	compare_int_int(testName, -7, value.ValNumberInt, t)
+
+	_, isValid = token_literal_to_value("nul")
+	compare_bool_bool(testName, false, isValid, t)
+
+	_, isValid = token_literal_to_value("012")
+	compare_bool_bool(testName, false, isValid, t)
+}
@@ -0,0 +1,331 @@
+/*
+Copyright (c) 2024, Balazs Nyiro, balazs.nyiro.ca@gmail.com
+All rights reserved.
+
+This source code (all file in this repo) is licensed
+under the Apache-2 style license found in the
+LICENSE file in the root directory of this source tree.
+
+This module: tape/arena based document representation.
+
+The idea from the comment above JSON_value: a value has only an Id,
+and every type has a special storage. No map is allocated per object,
+and no struct is copied per level:
+
+  - tape:    one flat slice, every elem of the document in src order (preorder).
+             An object is followed by its key-value pairs, an array by its elems.
+             A container knows the tape position after its whole subtree, so
+             a subtree can be skipped with one step.
+  - strData: every string and key, unescaped, in one big string.
+             A string elem has only a from/to position in it.
+  - ints, floats: the numbers.
+
+Node is a cheap handle (document + tape position) for navigation.
+*/
+
+package jyp
+
+import (
+	"errors"
+	"strconv"
+)
+
+type Document struct {
+	tape    []docElem
+	strData string
+	ints    []int
+	floats  []float64
+}
+
+type docElem struct {
+	/* { [     containers
+	   k       object key (always followed by the value)
+	   "       string
+	   I F     integer, float
+	   b n     bool, null                           */
+	elemType rune
+
+	/* the meaning depends on the type:
+	   containers: val1 = num of children (object: num of keys), val2 = tape position after the subtree
+	   k, ":       val1, val2 = from/to position in strData
+	   I, F:       val1 = position in ints/floats
+	   b:          val1 = 0: false, 1: true                                                            */
+	val1 int
+	val2 int
+}
+
+// Node is a handle of one value in the Document. The zero Node is an invalid/missing value
+type Node struct {
+	doc *Document
+	id  int // position in the tape
+}
+
+func DocumentParse(srcStr string) (*Document, []error) {
+	return DocumentParseBytes([]byte(srcStr))
+}
+
+func DocumentParseBytes(src []byte) (*Document, []error) {
+	tokensTable := stepA__tokensTableDetect_structuralTokens_strings_L1(src)
+	errorsCollected := stepB__JSON_validation_L1(src, tokensTable, Options{})
+	if len(errorsCollected) > 0 {
+		return &Document{}, errorsCollected
+	}
+	return stepC__JSON_document_building__L1(src, tokensTable)
+}
+
+// the token table has to be validated before this step
+func stepC__JSON_document_building__L1(src []byte, tokensTable tokenElems) (*Document, []error) { // TESTED
+	errorsCollected := []error{}
+	doc := Document{tape: make([]docElem, 0, len(tokensTable)/2+1)}
+	strData := make([]byte, 0, len(src)/2)
+
+	containersOpen := []int{} // tape positions of the open containers
+	keyWanted := false
+
+	childAdd := func() { // a new value is added to the actual container
+		if len(containersOpen) > 0 {
+			doc.tape[containersOpen[len(containersOpen)-1]].val1++
+		}
+	}
+
+	for pos := 0; pos < len(tokensTable); pos++ {
+		token := tokensTable[pos]
+
+		switch token.tokenType {
+		case '{', '[':
+			childAdd()
+			containersOpen = append(containersOpen, len(doc.tape))
+			doc.tape = append(doc.tape, docElem{elemType: token.tokenType})
+			keyWanted = token.tokenType == '{'
+
+		case '}', ']':
+			posContainer := containersOpen[len(containersOpen)-1]
+			containersOpen = containersOpen[:len(containersOpen)-1]
+			doc.tape[posContainer].val2 = len(doc.tape)
+			keyWanted = false
+
+		case ',':
+			keyWanted = doc.tape[containersOpen[len(containersOpen)-1]].elemType == '{'
+
+		case ':':
+
+		case '"':
+			elemType := '"'
+			if keyWanted {
+				elemType = 'k'
+				keyWanted = false
+			}
+			if elemType == '"' {
+				childAdd()
+			}
+			posFrom := len(strData)
+			strData = append(strData, stringValueParsing_rawToInterpretedCharacters_L2(base__read_sourceCode_section_basedOnTokenPositions(src, token, true))...)
+			doc.tape = append(doc.tape, docElem{elemType: elemType, val1: posFrom, val2: len(strData)})
+
+		case 't', 'f':
+			childAdd()
+			doc.tape = append(doc.tape, docElem{elemType: 'b', val1: base__bool_to_int(token.tokenType == 't')})
+
+		case 'n':
+			childAdd()
+			doc.tape = append(doc.tape, docElem{elemType: 'n'})
+
+		default: // numbers
+			childAdd()
+			textInSrc := base__read_sourceCode_section_basedOnTokenPositions(src, token, false)
+			value, isNumber := numberValueParsing_textToNumber_L2(string(textInSrc))
+			if !isNumber {
+				errorsCollected = append(errorsCollected, newParseError(src, token.posInSrcFirst, token.tokenType, ErrInvalidLiteral, "invalid number: "+string(textInSrc)))
+				doc.tape = append(doc.tape, docElem{elemType: 'n'})
+			} else if value.ValType == 'I' {
+				doc.tape = append(doc.tape, docElem{elemType: 'I', val1: len(doc.ints)})
+				doc.ints = append(doc.ints, value.ValNumberInt)
+			} else {
+				doc.tape = append(doc.tape, docElem{elemType: 'F', val1: len(doc.floats)})
+				doc.floats = append(doc.floats, value.ValNumberFloat)
+			}
+		}
+	}
+	doc.strData = string(strData)
+	return &doc, errorsCollected
+}
+
+func (doc *Document) Root() Node {
+	if len(doc.tape) == 0 {
+		return Node{}
+	}
+	return Node{doc: doc, id: 0}
+}
+
+// the tape position after the node, with its whole subtree
+func (doc *Document) posNext(id int) int {
+	elemType := doc.tape[id].elemType
+	if elemType == '{' || elemType == '[' {
+		return doc.tape[id].val2
+	}
+	return id + 1
+}
+
+////////////////////////////////////////////////////////////////////////////////////
+
+func (n Node) IsValid() bool {
+	return n.doc != nil
+}
+
+// { [ " I F b n   - 0 if the node is invalid
+func (n Node) Type() rune {
+	if n.doc == nil {
+		return 0
+	}
+	return n.doc.tape[n.id].elemType
+}
+
+func (n Node) Str() string {
+	if n.Type() != '"' {
+		return ""
+	}
+	elem := n.doc.tape[n.id]
+	return n.doc.strData[elem.val1:elem.val2] // no copy, this is a part of the big string storage
+}
+
+func (n Node) Int() int {
+	if n.Type() != 'I' {
+		return 0
+	}
+	return n.doc.ints[n.doc.tape[n.id].val1]
+}
+
+func (n Node) Float() float64 {
+	if n.Type() != 'F' {
+		return 0
+	}
+	return n.doc.floats[n.doc.tape[n.id].val1]
+}
+
+func (n Node) Bool() bool {
+	return n.Type() == 'b' && n.doc.tape[n.id].val1 == 1
+}
+
+// num of elems in an array, num of keys in an object
+func (n Node) Len() int {
+	if n.Type() != '{' && n.Type() != '[' {
+		return 0
+	}
+	return n.doc.tape[n.id].val1
+}
+
+// ask ONE indexed elem from an array
+func (n Node) Arr(index int) (Node, error) {
+	if n.Type() != '[' {
+		return Node{}, errors.New(errorPrefix + "node is not an array")
+	}
+	if index < 0 || index >= n.Len() {
+		return Node{}, errors.New(errorPrefix + "index (" + strconv.Itoa(index) + ") is not in array")
+	}
+	id := n.id + 1
+	for ; index > 0; index-- {
+		id = n.doc.posNext(id) // the previous elems are skipped with their subtrees
+	}
+	return Node{doc: n.doc, id: id}, nil
+}
+
+// the elems of an array
+func (n Node) Elems() []Node {
+	if n.Type() != '[' {
+		return nil
+	}
+	nodes := make([]Node, 0, n.Len())
+	for id := n.id + 1; id < n.doc.tape[n.id].val2; id = n.doc.posNext(id) {
+		nodes = append(nodes, Node{doc: n.doc, id: id})
+	}
+	return nodes
+}
+
+// the keys of an object, in src order
+func (n Node) Keys() []string {
+	if n.Type() != '{' {
+		return nil
+	}
+	keys := make([]string, 0, n.Len())
+	for id := n.id + 1; id < n.doc.tape[n.id].val2; id = n.doc.posNext(id + 1) {
+		keyElem := n.doc.tape[id]
+		keys = append(keys, n.doc.strData[keyElem.val1:keyElem.val2])
+	}
+	return keys
+}
+
+// the value of a key in an object. if a key is duplicated, the last one is used (as in JSON_value)
+func (n Node) Obj(key string) (Node, error) {
+	if n.Type() != '{' {
+		return Node{}, errors.New(errorPrefix + "node is not an object")
+	}
+	found := Node{}
+	for id := n.id + 1; id < n.doc.tape[n.id].val2; id = n.doc.posNext(id + 1) {
+		keyElem := n.doc.tape[id]
+		if n.doc.strData[keyElem.val1:keyElem.val2] == key {
+			found = Node{doc: n.doc, id: id + 1}
+		}
+	}
+	if found.doc == nil {
+		return Node{}, errors.New(errorPrefix + "unknown object key (key:" + key + ")")
+	}
+	return found, nil
+}
+
+// the same path handling as in JSON_value.GetPath: GetPath("/personal/list")
+func (n Node) GetPath(keysMerged string) (Node, error) {
+	if len(keysMerged) < 2 {
+		return Node{}, errors.New(errorPrefix + "missing separator and key(s) in merged GetPath")
+	}
+	keys, _ := ObjPath_merged_expand__split_with_first_char(keysMerged)
+	return n.GetPathKeys(keys)
+}
+
+func (n Node) GetPathKeys(keysEmbedded []string) (Node, error) {
+	if len(keysEmbedded) < 1 {
+		return Node{}, errors.New(errorPrefix + "missing object keys (no keys are passed)")
+	}
+	nodeNow := n
+	for _, key := range keysEmbedded {
+		nodeNext, err := nodeNow.Obj(key)
+		if err != nil {
+			return Node{}, err
+		}
+		nodeNow = nodeNext
+	}
+	return nodeNow, nil
+}
+
+// conversion to the classic representation, for compatibility
+func (doc *Document) To_JSON_value() JSON_value {
+	return doc.Root().To_JSON_value()
+}
+
+func (n Node) To_JSON_value() JSON_value {
+	switch n.Type() {
+	case '{':
+		value := NewObj()
+		for id := n.id + 1; id < n.doc.tape[n.id].val2; id = n.doc.posNext(id + 1) {
+			keyElem := n.doc.tape[id]
+			value.valObject_set(n.doc.strData[keyElem.val1:keyElem.val2], Node{doc: n.doc, id: id + 1}.To_JSON_value())
+		}
+		return value
+	case '[':
+		value := JSON_value{ValType: '[', ValArray: make([]JSON_value, 0, n.Len())}
+		for _, child := range n.Elems() {
+			value.ValArray = append(value.ValArray, child.To_JSON_value())
+		}
+		return value
+	case '"':
+		return NewStr(n.Str())
+	case 'I':
+		return NewNumInt(n.Int())
+	case 'F':
+		return NewNumFloat(n.Float())
+	case 'b':
+		return NewBool(n.Bool())
+	case 'n':
+		return NewNull()
+	}
+	return JSON_value{}
+}
@@ -0,0 +1,71 @@
+/*
+Copyright (c) 2024, Balazs Nyiro, balazs.nyiro.ca@gmail.com
+All rights reserved.
+
+This source code (all file in this repo) is licensed
+under the Apache-2 style license found in the
+LICENSE file in the root directory of this source tree.
+
+*/
+
+package jyp
+
+import "testing"
+
+// go test -v -run Test_DocumentParse
+func Test_DocumentParse(t *testing.T) {
+	funName := "Test_DocumentParse"
+	testName := funName + "_navigation"
+
+	src := `{"name": "Ősz\tÉ", "nums": [1, -2.5, {"deep": [true, null]}, [], {}], "empty": {}, "f": false}`
+	doc, errorsCollected := DocumentParse(src)
+	compare_int_int(testName, 0, len(errorsCollected), t)
+
+	root := doc.Root()
+	compare_rune_rune(testName, '{', root.Type(), t)
+	compare_int_int(testName, 4, root.Len(), t)
+	keys := root.Keys()
+	compare_str_str(testName, "name", keys[0], t)
+	compare_str_str(testName, "f", keys[3], t)
+
+	name, _ := root.Obj("name")
+	compare_str_str(testName, "Ősz\tÉ", name.Str(), t)
+
+	nums, _ := root.Obj("nums")
+	compare_int_int(testName, 5, nums.Len(), t)
+	first, _ := nums.Arr(0)
+	compare_int_int(testName, 1, first.Int(), t)
+	second, _ := nums.Arr(1)
+	compare_flt_flt(testName, -2.5, second.Float(), t)
+	fourth, _ := nums.Arr(3) // the subtree of the third elem is skipped
+	compare_rune_rune(testName, '[', fourth.Type(), t)
+	compare_int_int(testName, 0, fourth.Len(), t)
+	compare_int_int(testName, 5, len(nums.Elems()), t)
+
+	deepNull, _ := root.GetPath("/nums")
+	deepElem, _ := deepNull.Arr(2)
+	deepArr, _ := deepElem.GetPathKeys([]string{"deep"})
+	deepTrue, _ := deepArr.Arr(0)
+	compare_bool_bool(testName, true, deepTrue.Bool(), t)
+
+	f, _ := root.Obj("f")
+	compare_rune_rune(testName, 'b', f.Type(), t)
+	compare_bool_bool(testName, false, f.Bool(), t)
+
+	testName = funName + "_missing"
+	_, err := root.Obj("missing")
+	compare_bool_bool(testName, true, err != nil, t)
+	_, err = nums.Arr(5)
+	compare_bool_bool(testName, true, err != nil, t)
+	_, err = name.Arr(0)
+	compare_bool_bool(testName, true, err != nil, t)
+
+	testName = funName + "_conversion"
+	valueClassic, _ := JsonParse(src)
+	compare_str_str(testName, valueClassic.Repr(), doc.To_JSON_value().Repr(), t)
+
+	testName = funName + "_errors"
+	doc, errorsCollected = DocumentParse(`[1 2]`)
+	compare_int_int(testName, 1, len(errorsCollected), t)
+	compare_bool_bool(testName, false, doc.Root().IsValid(), t)
+}
@@ -0,0 +1,131 @@
+/*
+Copyright (c) 2024, Balazs Nyiro, balazs.nyiro.ca@gmail.com
+All rights reserved.
+
+This source code (all file in this repo) is licensed
+under the Apache-2 style license found in the
+LICENSE file in the root directory of this source tree.
+
+This module: streaming encoder, JSON values are written into an io.Writer.
+
+Repr() builds the output in one string, with concatenation on every level.
+The Encoder writes the same text directly into a buffered writer, so
+the whole output is never in the memory:
+
+	encoder := jyp.NewEncoder(os.Stdout)
+	encoder.SetIndent("  ", 0) // as in Repr_tuned(), or SetOptions(jyp.ReprOptions{Indent: 2})
+	err := encoder.Encode(value)
+
+Every value is closed with a newline, so more values can be written into the same stream.
+The output of one value is the same as its Repr_tuned()/ReprWithOptions() text.
+*/
+
+package jyp
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"strconv"
+)
+
+type Encoder struct {
+	writer  *bufio.Writer
+	indent  string // one indentation level. "": compact, one line output
+	level   int    // the start level, as in Repr_tuned
+	opts    ReprOptions
+	scratch []byte // reused buffer for the number conversions
+}
+
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{writer: bufio.NewWriter(w)}
+}
+
+// the indentation of Repr_tuned: indent is one level, level moves the output to the right
+func (e *Encoder) SetIndent(indent string, level int) {
+	e.indent = indent
+	e.level = level
+}
+
+// the options of ReprWithOptions. opts.Indent > 0 sets the indentation, too
+func (e *Encoder) SetOptions(opts ReprOptions) {
+	e.opts = opts
+	if opts.Indent > 0 {
+		e.SetIndent(base__prefixGenerator_for_repr(" ", opts.Indent), 0)
+	}
+}
+
+// the value is written with a closing newline, and the buffer is flushed
+func (e *Encoder) Encode(value JSON_value) error {
+	e.value_write(value, e.level)
+	e.writer.WriteByte('\n')
+	return e.writer.Flush() // the first write error of the buffered writer is returned here
+}
+
+// the same layout as in repr_options_tuned
+func (e *Encoder) value_write(v JSON_value, level int) {
+	newLine := ""
+	colon := ":"
+	if len(e.indent) > 0 {
+		newLine = "\n"
+		colon = ": "
+	}
+
+	switch v.ValType {
+	case '"':
+		e.writer.WriteString(stringValueRepr_interpretedToRaw_L2(v.ValRunes, e.opts.EnsureASCII, e.opts.HTMLSafe))
+	case 'I', 'F':
+		if v.ValNumberRaw != "" {
+			e.writer.WriteString(v.ValNumberRaw)
+		} else if v.ValType == 'I' {
+			e.scratch = strconv.AppendInt(e.scratch[:0], int64(v.ValNumberInt), 10)
+			e.writer.Write(e.scratch)
+		} else if math.IsInf(v.ValNumberFloat, 0) || math.IsNaN(v.ValNumberFloat) { // as in Repr()
+			e.writer.WriteString("null")
+		} else {
+			e.scratch = strconv.AppendFloat(e.scratch[:0], v.ValNumberFloat, 'f', -1, 64)
+			e.writer.Write(e.scratch)
+		}
+	case 'b':
+		if v.ValBool {
+			e.writer.WriteString("true")
+		} else {
+			e.writer.WriteString("false")
+		}
+	case 'n', '?': // '?': a broken node of the recovered tree
+		e.writer.WriteString("null")
+	case '{':
+		e.prefix_write(level)
+		e.writer.WriteByte('{')
+		e.writer.WriteString(newLine)
+		for counter, childKey := range v.valObject_keys(e.opts.KeysInsertionOrder) {
+			e.prefix_write(level + 1)
+			e.writer.WriteString(stringValueRepr_interpretedToRaw_L2(childKey, e.opts.EnsureASCII, e.opts.HTMLSafe))
+			e.writer.WriteString(colon)
+			e.value_write(v.ValObject[childKey], level+1)
+			e.writer.WriteString(base__separator_set_if_no_last_elem(counter, len(v.ValObject), ","))
+			e.writer.WriteString(newLine)
+		}
+		e.prefix_write(level)
+		e.writer.WriteByte('}')
+	case '[':
+		e.prefix_write(level)
+		e.writer.WriteByte('[')
+		e.writer.WriteString(newLine)
+		for counter, child := range v.ValArray {
+			e.prefix_write(level + 2) // prefix2 + indent in repr_options_tuned
+			e.value_write(child, level+1)
+			e.writer.WriteString(base__separator_set_if_no_last_elem(counter, len(v.ValArray), ","))
+			e.writer.WriteString(newLine)
+		}
+		e.prefix_write(level)
+		e.writer.WriteByte(']')
+	}
+}
+
+// the indentation of the level, without building the prefix string
+func (e *Encoder) prefix_write(level int) {
+	for ; level > 0 && len(e.indent) > 0; level-- {
+		e.writer.WriteString(e.indent)
+	}
+}
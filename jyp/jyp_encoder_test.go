@@ -0,0 +1,114 @@
+/*
+Copyright (c) 2024, Balazs Nyiro, balazs.nyiro.ca@gmail.com
+All rights reserved.
+
+This source code (all file in this repo) is licensed
+under the Apache-2 style license found in the
+LICENSE file in the root directory of this source tree.
+
+*/
+
+package jyp
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"runtime"
+	"testing"
+)
+
+// go test -v -run Test_Encoder
+func Test_Encoder(t *testing.T) {
+	funName := "Test_Encoder"
+	src := `{"name": "web", "tags": ["a", [], [1, [2, {}]], {"b": [true, null]}], "price": 1.50, "limit": 1e3, "n": -7,
+	         "spec": {"replicas": 2, "empty": {}, "text": "line\nbreak \"q\" \u00e9"}}`
+	root, _ := JsonParseWithOptions(src, Options{NumbersRaw: true})
+	root.ValObject["broken"] = JSON_value{ValType: '?'}
+	root.ValObject["numbers"] = NewArr(NewNumInt(-12), NewNumFloat(0.25), NewNumFloat(math.Inf(-1)), NewNumFloat(math.NaN()))
+
+	testName := funName + "_same_as_Repr_tuned"
+	for _, indent := range []string{"", "  ", "\t"} {
+		for level := 0; level < 3; level++ {
+			out := bytes.Buffer{}
+			encoder := NewEncoder(&out)
+			encoder.SetIndent(indent, level)
+			compare_bool_bool(testName, true, encoder.Encode(root) == nil, t)
+			compare_str_str(testName, root.Repr_tuned(indent, level)+"\n", out.String(), t)
+		}
+	}
+
+	testName = funName + "_same_as_ReprWithOptions"
+	root, _ = JsonParse(`{"z": "<é😀>", "a": [1, 2.5, true, null, {}], "m": {"x": []}}`)
+	for _, opts := range []ReprOptions{{}, {Indent: 4}, {EnsureASCII: true, HTMLSafe: true}, {KeysInsertionOrder: true, Indent: 1}} {
+		out := bytes.Buffer{}
+		encoder := NewEncoder(&out)
+		encoder.SetOptions(opts)
+		_ = encoder.Encode(root)
+		compare_str_str(testName, root.ReprWithOptions(opts)+"\n", out.String(), t)
+	}
+
+	testName = funName + "_more_values"
+	out := bytes.Buffer{}
+	encoder := NewEncoder(&out)
+	_ = encoder.Encode(NewNumInt(1))
+	_ = encoder.Encode(NewArr(NewStr("a")))
+	compare_str_str(testName, "1\n[\"a\"]\n", out.String(), t)
+
+	testName = funName + "_non_finite_float" // no JSON form, written as null
+	out.Reset()
+	_ = encoder.Encode(NewArr(NewNumFloat(math.Inf(1)), NewNumFloat(math.NaN())))
+	compare_str_str(testName, "[null,null]\n", out.String(), t)
+
+	testName = funName + "_write_error"
+	encoder = NewEncoder(writerFailingForTest{})
+	compare_bool_bool(testName, true, errors.Is(encoder.Encode(root), io.ErrShortWrite), t)
+}
+
+type writerFailingForTest struct{}
+
+func (w writerFailingForTest) Write(p []byte) (int, error) {
+	return 0, io.ErrShortWrite
+}
+
+// large-file.json, or the generated stand-in if it is not downloaded, see performance_notes.txt
+// go test -run NONE -bench large_file -benchmem
+func benchmark_large_file_root(b *testing.B) JSON_value {
+	src, srcName := large_file_src_for_test()
+	b.Logf("src: %s, %d bytes", srcName, len(src))
+	root, _ := JsonParseBytes(src)
+	runtime.GC() // the garbage of the parsing is not collected in the measured loop
+	b.ResetTimer()
+	return root
+}
+
+func BenchmarkEncoder_large_file(b *testing.B) {
+	root := benchmark_large_file_root(b)
+	for i := 0; i < b.N; i++ {
+		_ = NewEncoder(io.Discard).Encode(root)
+	}
+}
+
+func BenchmarkRepr_large_file(b *testing.B) {
+	root := benchmark_large_file_root(b)
+	for i := 0; i < b.N; i++ {
+		_, _ = io.WriteString(io.Discard, root.Repr())
+	}
+}
+
+func BenchmarkEncoder_large_file_indented(b *testing.B) {
+	root := benchmark_large_file_root(b)
+	for i := 0; i < b.N; i++ {
+		encoder := NewEncoder(io.Discard)
+		encoder.SetIndent("  ", 0)
+		_ = encoder.Encode(root)
+	}
+}
+
+func BenchmarkRepr_large_file_indented(b *testing.B) {
+	root := benchmark_large_file_root(b)
+	for i := 0; i < b.N; i++ {
+		_, _ = io.WriteString(io.Discard, root.Repr(2))
+	}
+}
@@ -0,0 +1,182 @@
+/*
+Copyright (c) 2024, Balazs Nyiro, balazs.nyiro.ca@gmail.com
+All rights reserved.
+
+This source code (all file in this repo) is licensed
+under the Apache-2 style license found in the
+LICENSE file in the root directory of this source tree.
+
+This module: positioned parse errors.
+
+Every problem that is detected in the src is reported with a ParseError.
+The Kind of the error is one of the Err... sentinel values, so the caller can check it:
+
+	if errors.Is(err, jyp.ErrMissingComma) { ... }
+
+	var parseErr jyp.ParseError
+	if errors.As(err, &parseErr) { fmt.Println(parseErr.Line, parseErr.Column) }
+*/
+
+package jyp
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// error kinds, usable with errors.Is()
+var (
+	ErrEmptySrc          = errors.New("empty src, there is no JSON value")
+	ErrInvalidLiteral    = errors.New("invalid literal")
+	ErrUnclosedString    = errors.New("unclosed string")
+	ErrUnexpectedToken   = errors.New("unexpected token")
+	ErrMissingColon      = errors.New("missing colon")
+	ErrMissingComma      = errors.New("missing comma")
+	ErrMissingValue      = errors.New("missing value")
+	ErrMissingKey        = errors.New("missing object key")
+	ErrTrailingComma     = errors.New("trailing comma")
+	ErrKeyNotString      = errors.New("object key is not a string")
+	ErrUnpairedCloser    = errors.New("unpaired closer")
+	ErrUnclosedContainer = errors.New("unclosed container")
+	ErrInvalidUTF8       = errors.New("invalid utf8 byte sequence in string")
+	ErrUnclosedComment   = errors.New("unclosed block comment")
+	ErrInvalidEscape     = errors.New("invalid escape sequence in string")
+	ErrControlCharacter  = errors.New("unescaped control character in string")
+	ErrDuplicateKey      = errors.New("duplicate object key")
+	ErrLimitExceeded     = errors.New("resource limit exceeded")
+)
+
+// the excerpt in Error() shows max this many runes before/after the problem
+const parseErrorExcerptHalfWidth = 40
+
+type ParseError struct {
+	Kind error  // one of the Err... sentinels
+	Msg  string // detailed description
+
+	Offset     int // rune position in the src, 0 based
+	ByteOffset int // byte position in the src, 0 based
+	Line       int // 1 based
+	Column     int // 1 based, counted in runes
+
+	TokenType rune // the type of the token where the problem was detected (see tokenElem)
+
+	excerpt      string // the line of the problem, maybe shortened
+	excerptCaret string // the prefix of the ^ char under the problem
+}
+
+func (e ParseError) Error() string {
+	out := errorPrefix + e.Msg +
+		" (line " + strconv.Itoa(e.Line) +
+		", column " + strconv.Itoa(e.Column) +
+		", offset " + strconv.Itoa(e.Offset) +
+		", byte offset " + strconv.Itoa(e.ByteOffset) + ")"
+	if e.excerpt != "" {
+		out += "\n" + e.excerpt + "\n" + e.excerptCaret + "^"
+	}
+	return out
+}
+
+// errors.Is(err, ErrMissingComma) works with this
+func (e ParseError) Unwrap() error {
+	return e.Kind
+}
+
+// if the src is part of a bigger stream, the positions are moved with the start position of the src.
+// lineStart and columnStart are 1 based
+func (e ParseError) shifted(posRuneStart, posByteStart, lineStart, columnStart int) ParseError { // TESTED
+	if e.Line == 1 && columnStart > 1 {
+		e.Column += columnStart - 1
+		e.excerpt, e.excerptCaret = "", "" // the line before the src is unknown, the caret would be misaligned
+	}
+	e.Line += lineStart - 1
+	e.Offset += posRuneStart
+	e.ByteOffset += posByteStart
+	return e
+}
+
+// posByte can be len(src) too, if the problem is at the end of the src
+func newParseError(src []byte, posByte int, tokenType rune, kind error, msg string) ParseError { // TESTED
+	return newSrcPositionCounter(src).parseError(posByte, tokenType, kind, msg)
+}
+
+// with more errors, one counter is used in the order of the errors, so the src is read only once.
+// The excerpt is cut from a fixed window around the problem, the length of the line doesn't matter
+func (counter *srcPositionCounter) parseError(posByte int, tokenType rune, kind error, msg string) ParseError { // TESTED
+	src := counter.src
+	if posByte > len(src) {
+		posByte = len(src)
+	}
+	if posByte < 0 {
+		posByte = 0
+	}
+	position := counter.position(posByte)
+
+	windowBytes := (parseErrorExcerptHalfWidth + 2) * utf8.UTFMax // more than the excerpt, in runes
+	posFrom := posByte                                            // the window is in the line of the problem
+	for posFrom > 0 && posByte-posFrom < windowBytes && src[posFrom-1] != '\n' {
+		posFrom--
+	}
+	for posFrom < posByte && src[posFrom]&0xC0 == 0x80 { // the window starts with a full rune
+		posFrom++
+	}
+	posTo := posByte // the closing newline is not part of the line
+	for posTo < len(src) && posTo-posByte < windowBytes && src[posTo] != '\n' {
+		posTo++
+	}
+
+	excerpt, excerptCaret := base__excerpt_with_caret([]rune(string(src[posFrom:posTo])), utf8.RuneCount(src[posFrom:posByte]))
+	if msg == "" {
+		msg = kind.Error()
+	}
+	return ParseError{
+		Kind:         kind,
+		Msg:          msg,
+		Offset:       position.Offset,
+		ByteOffset:   posByte,
+		Line:         position.Line,
+		Column:       position.Column,
+		TokenType:    tokenType,
+		excerpt:      excerpt,
+		excerptCaret: excerptCaret,
+	}
+}
+
+// the line of the problem, and the prefix of the caret under the wanted column.
+// very long lines (minified json, for example) are shortened around the problem
+func base__excerpt_with_caret(lineRunes []rune, column0 int) (string, string) { // TESTED
+	for len(lineRunes) > 0 && lineRunes[len(lineRunes)-1] == '\r' {
+		lineRunes = lineRunes[:len(lineRunes)-1]
+	}
+
+	posFrom := 0
+	posTo := len(lineRunes)
+	prefix := ""
+	suffix := ""
+	if column0-parseErrorExcerptHalfWidth > 0 {
+		posFrom = column0 - parseErrorExcerptHalfWidth
+		prefix = "..."
+	}
+	if column0+parseErrorExcerptHalfWidth < posTo {
+		posTo = column0 + parseErrorExcerptHalfWidth
+		suffix = "..."
+	}
+
+	caret := []rune(prefix) // the caret prefix has the same width as the excerpt before the problem
+	for i := range caret {
+		caret[i] = ' '
+	}
+	for pos := posFrom; pos < column0 && pos < len(lineRunes); pos++ {
+		if lineRunes[pos] == '\t' {
+			caret = append(caret, '\t') // keep the tabs, so the caret is under the problem
+		} else {
+			caret = append(caret, ' ')
+		}
+	}
+	excerpt := prefix + string(lineRunes[posFrom:posTo]) + suffix
+	if strings.TrimSpace(excerpt) == "" {
+		return "", ""
+	}
+	return excerpt, string(caret)
+}
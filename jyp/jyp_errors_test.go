@@ -0,0 +1,96 @@
+/*
+Copyright (c) 2024, Balazs Nyiro, balazs.nyiro.ca@gmail.com
+All rights reserved.
+
+This source code (all file in this repo) is licensed
+under the Apache-2 style license found in the
+LICENSE file in the root directory of this source tree.
+
+*/
+
+package jyp
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// go test -v -run Test_newParseError
+func Test_newParseError(t *testing.T) {
+	funName := "Test_newParseError"
+	testName := funName + "_line_column"
+
+	src := []byte("{\n  \"név\": \"á\",\n  \"b\" 2\n}")
+	parseErr := newParseError(src, 24, '0', ErrMissingColon, "") // byte position
+	compare_int_int(testName, 3, parseErr.Line, t)
+	compare_int_int(testName, 7, parseErr.Column, t)
+	compare_int_int(testName, 22, parseErr.Offset, t)
+	compare_int_int(testName, 24, parseErr.ByteOffset, t) // é and á are 2 bytes long
+	compare_rune_rune(testName, '0', parseErr.TokenType, t)
+
+	testName = funName + "_excerpt"
+	wanted := `Error: missing colon (line 3, column 7, offset 22, byte offset 24)
+  "b" 2
+      ^`
+	compare_str_str(testName, wanted, parseErr.Error(), t)
+
+	testName = funName + "_shifted"
+	parseErrShifted := parseErr.shifted(100, 200, 10, 5) // not in the first line: the excerpt is kept
+	compare_int_int(testName, 12, parseErrShifted.Line, t)
+	compare_int_int(testName, 7, parseErrShifted.Column, t)
+	compare_int_int(testName, 122, parseErrShifted.Offset, t)
+	compare_str_str(testName, parseErr.excerpt, parseErrShifted.excerpt, t)
+	parseErrFirstLine := newParseError([]byte(`{"a" 1}`), 5, '0', ErrMissingColon, "").shifted(4, 4, 1, 5)
+	compare_int_int(testName, 10, parseErrFirstLine.Column, t)
+	compare_str_str(testName, "", parseErrFirstLine.excerpt, t)
+
+	testName = funName + "_errors_is_as"
+	var err error = parseErr
+	compare_bool_bool(testName, true, errors.Is(err, ErrMissingColon), t)
+	compare_bool_bool(testName, false, errors.Is(err, ErrMissingComma), t)
+	var parseErrBack ParseError
+	compare_bool_bool(testName, true, errors.As(err, &parseErrBack), t)
+	compare_int_int(testName, 3, parseErrBack.Line, t)
+}
+
+// go test -v -run Test_base__excerpt_with_caret
+func Test_base__excerpt_with_caret(t *testing.T) {
+	funName := "Test_base__excerpt_with_caret"
+	testName := funName + "_tab"
+
+	excerpt, caret := base__excerpt_with_caret([]rune("\t[1 2]\r"), 4)
+	compare_str_str(testName, "\t[1 2]", excerpt, t)
+	compare_str_str(testName, "\t   ", caret, t)
+
+	testName = funName + "_long_line"
+	line := []rune(strings.Repeat("1,", 100) + "x" + strings.Repeat(",2", 100))
+	excerpt, caret = base__excerpt_with_caret(line, 200)
+	compare_str_str(testName, "..."+strings.Repeat("1,", 20)+"x"+strings.Repeat(",2", 19)+",...", excerpt, t)
+	compare_int_int(testName, 43, len(caret), t)
+}
+
+// go test -v -run Test_parseError_many_errors
+func Test_parseError_many_errors(t *testing.T) {
+	testName := "Test_parseError_many_errors" // one long line, every elem is invalid: the cost of one error is not growing with its offset
+	src := "[" + strings.Repeat("x,", 100000) + "1]"
+	timeStart := time.Now()
+	_, errorsCollected := JsonParse(src)
+	compare_bool_bool(testName, true, time.Since(timeStart) < 5*time.Second, t)
+	compare_int_int(testName, 100000, len(errorsCollected), t)
+
+	var parseErr ParseError
+	compare_bool_bool(testName, true, errors.As(errorsCollected[len(errorsCollected)-1], &parseErr), t)
+	compare_int_int(testName, 199999, parseErr.Offset, t)
+	compare_int_int(testName, 200000, parseErr.Column, t)
+	compare_str_str(testName, "..."+strings.Repeat("x,", 20)+"x,1]", parseErr.excerpt, t)
+
+	testName = "Test_parseError_many_errors_same_counter" // the counter gives the same positions as a new one
+	src = "{\"é\": x, \"b\"\n 2, \"\\q\" 3}"
+	positionCounter := newSrcPositionCounter([]byte(src))
+	for _, posByte := range []int{8, 22, 21, 14, 3, 25} {
+		compare_str_str(testName, newParseError([]byte(src), posByte, '?', ErrInvalidLiteral, "").Error(),
+			positionCounter.parseError(posByte, '?', ErrInvalidLiteral, "").Error(), t)
+	}
+}
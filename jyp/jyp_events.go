@@ -0,0 +1,144 @@
+/*
+Copyright (c) 2024, Balazs Nyiro, balazs.nyiro.ca@gmail.com
+All rights reserved.
+
+This source code (all file in this repo) is licensed
+under the Apache-2 style license found in the
+LICENSE file in the root directory of this source tree.
+
+This module: event driven (SAX style) parsing.
+
+The token table of stepA is walked, and the handler is called for every
+structural event - no JSON_value tree is built, only the scalar values are
+interpreted. If only a few fields are needed from a big document,
+this is cheaper than the full structure building of stepC.
+
+The received path is the position of the actual elem in the document:
+object keys, and array indexes as decimal strings, for example: ["items", "3", "id"].
+The path slice is reused between the calls - copy it if you want to keep it.
+*/
+
+package jyp
+
+import "strconv"
+
+type EventHandler interface {
+	OnObjectStart(path []string)
+	OnObjectEnd(path []string)
+	OnArrayStart(path []string)
+	OnArrayEnd(path []string)
+
+	// path: the path of the object, where the key is
+	OnKey(path []string, key string)
+
+	// strings, numbers, bools and null. path: the path of the value
+	OnValue(path []string, value JSON_value)
+
+	OnError(err error)
+}
+
+// EventHandlerBase can be embedded into a handler,
+// so only the really used event methods have to be implemented
+type EventHandlerBase struct{}
+
+func (EventHandlerBase) OnObjectStart(path []string)             {}
+func (EventHandlerBase) OnObjectEnd(path []string)               {}
+func (EventHandlerBase) OnArrayStart(path []string)              {}
+func (EventHandlerBase) OnArrayEnd(path []string)                {}
+func (EventHandlerBase) OnKey(path []string, key string)         {}
+func (EventHandlerBase) OnValue(path []string, value JSON_value) {}
+func (EventHandlerBase) OnError(err error)                       {}
+
+// the src is validated first: if there is any error, only OnError is called
+func JsonParseEvents(srcStr string, handler EventHandler) []error {
+	src := []byte(srcStr)
+	tokensTable := stepA__tokensTableDetect_structuralTokens_strings_L1(src)
+	errorsCollected := stepB__JSON_validation_L1(src, tokensTable, Options{})
+	if len(errorsCollected) == 0 {
+		errorsCollected = stepC__JSON_events_L1(src, tokensTable, handler)
+	}
+	for _, err := range errorsCollected {
+		handler.OnError(err)
+	}
+	return errorsCollected
+}
+
+// the token table has to be validated before this step
+func stepC__JSON_events_L1(src []byte, tokensTable tokenElems, handler EventHandler) []error { // TESTED
+	errorsCollected := []error{}
+	path := []string{}
+	containers := []rune{}  // the open containers, the last one is the innermost
+	arrayIndexes := []int{} // the index of the actual elem, in every open container
+	keyWanted := false      // in an object, after { and comma the next string is a key
+
+	// the value is in a container: its path elem is added
+	valueStart := func() {
+		if len(containers) > 0 && containers[len(containers)-1] == '[' {
+			arrayIndexes[len(arrayIndexes)-1]++
+			path = append(path, strconv.Itoa(arrayIndexes[len(arrayIndexes)-1]))
+		} // in objects, the key was added into the path
+	}
+	valueEnd := func() {
+		if len(containers) > 0 {
+			path = path[:len(path)-1]
+		}
+	}
+
+	for pos := 0; pos < len(tokensTable); pos++ {
+		token := tokensTable[pos]
+
+		switch token.tokenType {
+		case '{', '[':
+			valueStart()
+			if token.tokenType == '{' {
+				handler.OnObjectStart(path)
+				keyWanted = true
+			} else {
+				handler.OnArrayStart(path)
+			}
+			containers = append(containers, token.tokenType)
+			arrayIndexes = append(arrayIndexes, -1)
+
+		case '}', ']':
+			containers = containers[:len(containers)-1]
+			arrayIndexes = arrayIndexes[:len(arrayIndexes)-1]
+			if token.tokenType == '}' {
+				handler.OnObjectEnd(path)
+			} else {
+				handler.OnArrayEnd(path)
+			}
+			valueEnd()
+			keyWanted = false
+
+		case ',':
+			keyWanted = containers[len(containers)-1] == '{'
+
+		case ':':
+
+		case '"':
+			textInSrc := base__read_sourceCode_section_basedOnTokenPositions(src, token, false)
+			if keyWanted {
+				key := stringValueParsing_rawToInterpretedCharacters_L2(textInSrc[1:len(textInSrc)-1])
+				handler.OnKey(path, key)
+				path = append(path, key)
+				keyWanted = false
+				continue
+			}
+			valueStart()
+			handler.OnValue(path, NewString__rawToInterpreted__QuotedBothEnd(textInSrc))
+			valueEnd()
+
+		default: // numbers, true, false, null
+			valueStart()
+			textInSrc := base__read_sourceCode_section_basedOnTokenPositions(src, token, false)
+			value, isValid := token_literal_to_value(string(textInSrc))
+			if isValid {
+				handler.OnValue(path, value)
+			} else {
+				errorsCollected = append(errorsCollected, newParseError(src, token.posInSrcFirst, token.tokenType, ErrInvalidLiteral, "invalid number: "+string(textInSrc)))
+			}
+			valueEnd()
+		}
+	}
+	return errorsCollected
+}
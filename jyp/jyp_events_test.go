@@ -0,0 +1,97 @@
+/*
+Copyright (c) 2024, Balazs Nyiro, balazs.nyiro.ca@gmail.com
+All rights reserved.
+
+This source code (all file in this repo) is licensed
+under the Apache-2 style license found in the
+LICENSE file in the root directory of this source tree.
+
+*/
+
+package jyp
+
+import (
+	"strings"
+	"testing"
+)
+
+// every event is saved as a line of text
+type eventsCollector struct {
+	events []string
+}
+
+func (c *eventsCollector) OnObjectStart(path []string)     { c.add("objStart", path, "") }
+func (c *eventsCollector) OnObjectEnd(path []string)       { c.add("objEnd", path, "") }
+func (c *eventsCollector) OnArrayStart(path []string)      { c.add("arrStart", path, "") }
+func (c *eventsCollector) OnArrayEnd(path []string)        { c.add("arrEnd", path, "") }
+func (c *eventsCollector) OnKey(path []string, key string) { c.add("key", path, key) }
+func (c *eventsCollector) OnValue(path []string, value JSON_value) {
+	c.add("value", path, value.Repr())
+}
+func (c *eventsCollector) OnError(err error) { c.add("error", nil, "") }
+func (c *eventsCollector) add(event string, path []string, info string) {
+	c.events = append(c.events, event+" /"+strings.Join(path, "/")+" "+info)
+}
+
+// go test -v -run Test_JsonParseEvents
+func Test_JsonParseEvents(t *testing.T) {
+	funName := "Test_JsonParseEvents"
+	testName := funName + "_all_events"
+
+	collector := &eventsCollector{}
+	errorsCollected := JsonParseEvents(`{"a": [1, {"b": null}], "c": "C", "d": {}}`, collector)
+	compare_int_int(testName, 0, len(errorsCollected), t)
+
+	wanted := []string{
+		"objStart / ",
+		"key / a",
+		"arrStart /a ",
+		"value /a/0 1",
+		"objStart /a/1 ",
+		"key /a/1 b",
+		"value /a/1/b null",
+		"objEnd /a/1 ",
+		"arrEnd /a ",
+		"key / c",
+		`value /c "C"`,
+		"key / d",
+		"objStart /d ",
+		"objEnd /d ",
+		"objEnd / ",
+	}
+	compare_str_str(testName, strings.Join(wanted, "\n"), strings.Join(collector.events, "\n"), t)
+
+	testName = funName + "_root_scalar"
+	collector = &eventsCollector{}
+	JsonParseEvents(`42`, collector)
+	compare_str_str(testName, "value / 42", strings.Join(collector.events, "\n"), t)
+
+	testName = funName + "_errors"
+	collector = &eventsCollector{}
+	errorsCollected = JsonParseEvents(`[1 2]`, collector)
+	compare_int_int(testName, 1, len(errorsCollected), t)
+	compare_str_str(testName, "error / ", strings.Join(collector.events, "\n"), t)
+}
+
+// only one field is collected, the other methods come from EventHandlerBase
+type eventsOneField struct {
+	EventHandlerBase
+	ids []int
+}
+
+func (h *eventsOneField) OnValue(path []string, value JSON_value) {
+	if len(path) == 3 && path[0] == "items" && path[2] == "id" {
+		h.ids = append(h.ids, value.ValNumberInt)
+	}
+}
+
+// go test -v -run Test_JsonParseEvents_handlerBase
+func Test_JsonParseEvents_handlerBase(t *testing.T) {
+	funName := "Test_JsonParseEvents_handlerBase"
+	testName := funName + "_one_field"
+
+	handler := &eventsOneField{}
+	JsonParseEvents(`{"items": [{"id": 7, "name": "x"}, {"id": 8, "tags": [9]}]}`, handler)
+	compare_int_int(testName, 2, len(handler.ids), t)
+	compare_int_int(testName, 8, handler.ids[1], t)
+}
@@ -0,0 +1,212 @@
+/*
+Copyright (c) 2024, Balazs Nyiro, balazs.nyiro.ca@gmail.com
+All rights reserved.
+
+This source code (all file in this repo) is licensed
+under the Apache-2 style license found in the
+LICENSE file in the root directory of this source tree.
+
+This module: JSONC, json with comments - comment preserving round trip.
+
+	root, errs := jyp.JsonParseWithOptions(src, jyp.Options{Comments: true})
+	root.SetPath("/editor/fontSize", jyp.NewNumInt(14), false)
+	out := root.ReprWithComments(2)
+
+The comments are saved as trivia into the Comments of the values:
+  b  before: the comments in the lines before the value (before its key, in an object)
+  a  after:  the comments after the value in the same line (after its comma, too)
+  o  opener: the 'a' comments of the opener of an object/array, in the same line as the opener
+  i  inside: the comments before the closer of an object/array, after the last elem
+  e  end:    the comments in the lines after the root value, root only
+
+The tokenizer is the relaxed stepA (comments are detected there), the comment
+tokens are separated from the token table before stepB, and stepC attaches
+them to the built values. If a value is replaced with SetPath, the comments of
+the old value are kept.
+*/
+
+package jyp
+
+import "strings"
+
+type JSON_comment struct {
+	Position rune   // b: before, a: after, o: opener, i: inside, e: end
+	Text     string // with the comment markers: "// note", "/* note */"
+}
+
+// the comments of the token table, without the comment tokens
+type tokenComments struct {
+	before map[int][]string // token position -> the comments before the token, in separated lines
+	after  map[int][]string // token position -> the comments after the token, in the same line
+}
+
+// the comment tokens are removed, and saved by the position of their neighbour tokens
+func tokensTable_comments_separate(src []byte, tokensAll tokenElems) (tokenElems, tokenComments) { // TESTED
+	tokens := make(tokenElems, 0, len(tokensAll))
+	comments := tokenComments{before: map[int][]string{}, after: map[int][]string{}}
+
+	posLastEnd := -1         // the end of the last token or comment, in src
+	afterIsPossible := false // after a token, the comments are 'after' comments till the first newline
+	for _, token := range tokensAll {
+		if token.tokenType != '/' {
+			tokens = append(tokens, token)
+			posLastEnd = token.posInSrcLast
+			afterIsPossible = true
+			continue
+		}
+
+		text := string(base__read_sourceCode_section_basedOnTokenPositions(src, token, false))
+		if afterIsPossible && len(tokens) > 0 && !base__newline_in(src[posLastEnd+1:token.posInSrcFirst]) {
+			comments.after[len(tokens)-1] = append(comments.after[len(tokens)-1], text)
+		} else {
+			comments.before[len(tokens)] = append(comments.before[len(tokens)], text)
+			afterIsPossible = false
+		}
+		posLastEnd = token.posInSrcLast
+	}
+	return tokens, comments
+}
+
+// the comments of an array elem or an object member.
+// posFirst: the key in objects, posValueFirst: the first token of the value, posLast: the last token of the value
+func (comments tokenComments) of_child(tokensTable tokenElems, posFirst, posValueFirst, posLast int) []JSON_comment {
+	result := []JSON_comment{}
+	add := func(position rune, texts []string) {
+		for _, text := range texts {
+			result = append(result, JSON_comment{Position: position, Text: text})
+		}
+	}
+
+	for pos := posFirst; pos <= posValueFirst; pos++ {
+		add('b', comments.before[pos])
+		if pos < posValueFirst { // "key": // comment   -> the value is in the next line
+			add('b', comments.after[pos])
+		}
+	}
+
+	add('a', comments.after[posLast])
+	if posLast+1 < len(tokensTable) && tokensTable[posLast+1].tokenType == ',' {
+		add('a', comments.before[posLast+1])
+		add('a', comments.after[posLast+1])
+	}
+	return result
+}
+
+// the comments after the opener, in the same line, and the comments before the closer of a container
+func (comments tokenComments) of_container_inside(posOpener, posCloser int) []JSON_comment {
+	result := []JSON_comment{}
+	for _, text := range comments.after[posOpener] { // "editor": {  // font settings
+		result = append(result, JSON_comment{Position: 'o', Text: text})
+	}
+	for _, text := range comments.before[posCloser] {
+		result = append(result, JSON_comment{Position: 'i', Text: text})
+	}
+	return result
+}
+
+// the comments before and after the root value
+func (comments tokenComments) of_root(tokensTable tokenElems) []JSON_comment {
+	result := []JSON_comment{}
+	for _, text := range comments.before[0] {
+		result = append(result, JSON_comment{Position: 'b', Text: text})
+	}
+	for _, text := range comments.after[len(tokensTable)-1] {
+		result = append(result, JSON_comment{Position: 'a', Text: text})
+	}
+	for _, text := range comments.before[len(tokensTable)] { // in separated lines, after the root
+		result = append(result, JSON_comment{Position: 'e', Text: text})
+	}
+	return result
+}
+
+////////////////////////////////////////////////////////////////////////////////////
+
+// the comments are written back - the indentation has to be min 1,
+// because a // comment is closed with a newline. The keys are written in insertion order,
+// so a round trip of a settings file doesn't reorder the objects
+func (v JSON_value) ReprWithComments(indentationLength int) string {
+	return v.ReprWithCommentsOptions(ReprOptions{Indent: indentationLength, KeysInsertionOrder: true})
+}
+
+// opts.Indent is min 1 here, too. The zero opts.KeysInsertionOrder means sorted keys, as in ReprWithOptions
+func (v JSON_value) ReprWithCommentsOptions(opts ReprOptions) string {
+	if opts.Indent < 1 {
+		opts.Indent = 1
+	}
+	indent := base__prefixGenerator_for_repr(" ", opts.Indent)
+	out := v.comments_repr('b', "", "\n")
+	out += v.repr_comments_tuned(indent, 0, opts)
+	return out + v.comments_repr_after("") + "\n" + v.comments_repr('e', "", "\n")
+}
+
+func (v JSON_value) repr_comments_tuned(indent string, level int, opts ReprOptions) string {
+	prefix := base__prefixGenerator_for_repr(indent, level)
+	prefix2 := base__prefixGenerator_for_repr(indent, level+1)
+
+	commentsOpener := v.comments_repr_same_line('o', prefix2)
+	commentsInside := v.comments_repr('i', prefix2, "\n")
+
+	if v.ValType == '{' {
+		if len(v.ValObject) == 0 && commentsOpener == "" && commentsInside == "" {
+			return "{}"
+		}
+		out := "{" + commentsOpener + "\n"
+		for counter, childKey := range v.valObject_keys(opts.KeysInsertionOrder) {
+			comma := base__separator_set_if_no_last_elem(counter, len(v.ValObject), ",")
+			childVal := v.ValObject[childKey]
+			out += childVal.comments_repr('b', prefix2, "\n")
+			out += prefix2 + stringValueRepr_interpretedToRaw_L2(childKey, opts.EnsureASCII, opts.HTMLSafe) + ": " + childVal.repr_comments_tuned(indent, level+1, opts) + comma
+			out += childVal.comments_repr_after(prefix2) + "\n"
+		}
+		return out + commentsInside + prefix + "}"
+	}
+
+	if v.ValType == '[' {
+		if len(v.ValArray) == 0 && commentsOpener == "" && commentsInside == "" {
+			return "[]"
+		}
+		out := "[" + commentsOpener + "\n"
+		for counter, child := range v.ValArray {
+			comma := base__separator_set_if_no_last_elem(counter, len(v.ValArray), ",")
+			out += child.comments_repr('b', prefix2, "\n")
+			out += prefix2 + child.repr_comments_tuned(indent, level+1, opts) + comma
+			out += child.comments_repr_after(prefix2) + "\n"
+		}
+		return out + commentsInside + prefix + "]"
+	}
+	return v.ReprWithOptions(ReprOptions{EnsureASCII: opts.EnsureASCII, HTMLSafe: opts.HTMLSafe})
+}
+
+// every comment in a separated line
+func (v JSON_value) comments_repr(position rune, prefix, lineEnd string) string {
+	out := ""
+	for _, comment := range v.Comments {
+		if comment.Position == position {
+			out += prefix + comment.Text + lineEnd
+		}
+	}
+	return out
+}
+
+// the after comments are in the same line, but a // comment cannot be followed by anything
+func (v JSON_value) comments_repr_after(prefix string) string {
+	return v.comments_repr_same_line('a', prefix)
+}
+
+// the after and opener comments: after a // comment the next one is in a new line
+func (v JSON_value) comments_repr_same_line(position rune, prefix string) string {
+	out := ""
+	lineCommentIsOpen := false
+	for _, comment := range v.Comments {
+		if comment.Position != position {
+			continue
+		}
+		if lineCommentIsOpen {
+			out += "\n" + prefix + comment.Text
+		} else {
+			out += " " + comment.Text
+		}
+		lineCommentIsOpen = lineCommentIsOpen || strings.HasPrefix(comment.Text, "//")
+	}
+	return out
+}
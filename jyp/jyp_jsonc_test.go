@@ -0,0 +1,155 @@
+/*
+Copyright (c) 2024, Balazs Nyiro, balazs.nyiro.ca@gmail.com
+All rights reserved.
+
+This source code (all file in this repo) is licensed
+under the Apache-2 style license found in the
+LICENSE file in the root directory of this source tree.
+
+*/
+
+package jyp
+
+import "testing"
+
+var srcJsonc = `// user settings
+{
+  // the editor
+  "editor": { // font settings
+    "fontFamily": "Fira Code", // ligatures
+    "fontSize": 12, /* too small? */
+    "rulers": [
+      80, // git
+      120
+      // no more
+    ]
+  },
+  /* files
+     section */
+  "files": {
+    // nothing yet
+  }
+} // end
+`
+
+// go test -v -run Test_JsonParse_comments
+func Test_JsonParse_comments(t *testing.T) {
+	funName := "Test_JsonParse_comments"
+
+	testName := funName + "_trivia"
+	root, errorsCollected := JsonParseWithOptions(srcJsonc, Options{Comments: true})
+	compare_int_int(testName, 0, len(errorsCollected), t)
+
+	editor := root.ValObject["editor"]
+	compare_comments(testName+"_root", []JSON_comment{{'b', "// user settings"}, {'a', "// end"}}, root.Comments, t)
+	compare_comments(testName+"_editor", []JSON_comment{{'o', "// font settings"}, {'b', "// the editor"}}, editor.Comments, t)
+	compare_comments(testName+"_fontFamily", []JSON_comment{{'a', "// ligatures"}}, editor.ValObject["fontFamily"].Comments, t)
+	compare_comments(testName+"_fontSize", []JSON_comment{{'a', "/* too small? */"}}, editor.ValObject["fontSize"].Comments, t)
+	rulers := editor.ValObject["rulers"]
+	compare_comments(testName+"_rulers", []JSON_comment{{'i', "// no more"}}, rulers.Comments, t)
+	compare_comments(testName+"_rulers0", []JSON_comment{{'a', "// git"}}, rulers.ValArray[0].Comments, t)
+	compare_comments(testName+"_files", []JSON_comment{{'i', "// nothing yet"}, {'b', "/* files\n     section */"}}, root.ValObject["files"].Comments, t)
+
+	testName = funName + "_roundtrip_after_SetPath"
+	err := root.SetPath("/editor/fontSize", NewNumInt(14), false)
+	compare_bool_bool(testName, true, err == nil, t)
+	wanted := `// user settings
+{
+  // the editor
+  "editor": { // font settings
+    "fontFamily": "Fira Code", // ligatures
+    "fontSize": 14, /* too small? */
+    "rulers": [
+      80, // git
+      120
+      // no more
+    ]
+  },
+  /* files
+     section */
+  "files": {
+    // nothing yet
+  }
+} // end
+`
+	compare_str_str(testName, wanted, root.ReprWithComments(2), t)
+
+	testName = funName + "_reparse"
+	rootReparsed, errorsCollected := JsonParseWithOptions(root.ReprWithComments(2), Options{Comments: true})
+	compare_int_int(testName, 0, len(errorsCollected), t)
+	compare_str_str(testName, wanted, rootReparsed.ReprWithComments(2), t)
+
+	testName = funName + "_roundtrip_unchanged" // key order, empty containers, comments after the root
+	src := `// settings
+{
+  "zoom": 1,
+  "extensions": [],
+  "files": {},
+  "editor": {
+    "tabSize": 4,
+    "fontSize": 12 // small
+  }
+} // same line
+// own line, after the root
+`
+	root, errorsCollected = JsonParseWithOptions(src, Options{Comments: true})
+	compare_int_int(testName, 0, len(errorsCollected), t)
+	compare_comments(testName, []JSON_comment{{'b', "// settings"}, {'a', "// same line"}, {'e', "// own line, after the root"}}, root.Comments, t)
+	compare_str_str(testName, src, root.ReprWithComments(2), t)
+	root, _ = JsonParseWithOptions(`{"b": [], "a": {}}`, Options{Comments: true})
+	compare_str_str(testName+"_sorted", "{\n  \"a\": {},\n  \"b\": []\n}\n", root.ReprWithCommentsOptions(ReprOptions{Indent: 2}), t)
+
+	testName = funName + "_opener_line" // the comment after the opener stays in the line of the opener
+	src = `{ // root opener
+  "empty": [ // nothing
+  ],
+  "list": [ /* one */ /* two */
+    1
+  ]
+}
+`
+	root, errorsCollected = JsonParseWithOptions(src, Options{Comments: true})
+	compare_int_int(testName, 0, len(errorsCollected), t)
+	compare_comments(testName, []JSON_comment{{'o', "// root opener"}}, root.Comments, t)
+	compare_comments(testName, []JSON_comment{{'o', "// nothing"}}, root.ValObject["empty"].Comments, t)
+	compare_comments(testName, []JSON_comment{}, root.ValObject["list"].ValArray[0].Comments, t)
+	compare_str_str(testName, src, root.ReprWithComments(2), t)
+
+	testName = funName + "_relaxed_trailing_comma"
+	root, errorsCollected = JsonParseWithOptions(`{"a": [1, /* one */], // a
+}`, Options{Comments: true, Relaxed: true})
+	compare_int_int(testName, 0, len(errorsCollected), t)
+	compare_comments(testName, []JSON_comment{{'a', "/* one */"}}, root.ValObject["a"].ValArray[0].Comments, t)
+	compare_comments(testName, []JSON_comment{{'a', "// a"}}, root.ValObject["a"].Comments, t)
+
+	testName = funName + "_without_option"
+	_, errorsCollected = JsonParse(srcJsonc)
+	compare_bool_bool(testName, true, len(errorsCollected) > 0, t)
+
+	testName = funName + "_strict_except_comments"
+	_, errorsCollected = JsonParseWithOptions(`{'a': 1}`, Options{Comments: true})
+	compare_int_int(testName, 1, len(errorsCollected), t)
+	_, errorsCollected = JsonParseWithOptions(`{a: 1}`, Options{Comments: true})
+	compare_int_int(testName, 1, len(errorsCollected), t)
+}
+
+// go test -v -run Test_tokensTable_comments_separate
+func Test_tokensTable_comments_separate(t *testing.T) {
+	testName := "Test_tokensTable_comments_separate"
+
+	src := []byte("/*a*/ [1, /*b*/ // c\n /*d*/ 2]")
+	tokens, comments := tokensTable_comments_separate(src, stepA__tokensTableDetect_relaxed_L1(src))
+	compare_int_int(testName, 5, len(tokens), t)
+	compare_int_int(testName, 1, len(comments.before[0]), t)
+	compare_int_int(testName, 2, len(comments.after[2]), t) // after the comma, in the same line
+	compare_str_str(testName, "// c", comments.after[2][1], t)
+	compare_str_str(testName, "/*d*/", comments.before[3][0], t)
+}
+
+func compare_comments(testName string, wanted, received []JSON_comment, t *testing.T) {
+	compare_int_int(testName+"_len", len(wanted), len(received), t)
+	for pos := range wanted {
+		compare_rune_rune(testName, wanted[pos].Position, received[pos].Position, t)
+		compare_str_str(testName, wanted[pos].Text, received[pos].Text, t)
+	}
+}
@@ -0,0 +1,172 @@
+/*
+Copyright (c) 2024, Balazs Nyiro, balazs.nyiro.ca@gmail.com
+All rights reserved.
+
+This source code (all file in this repo) is licensed
+under the Apache-2 style license found in the
+LICENSE file in the root directory of this source tree.
+
+This module: lazy parsing - the tree is not built, only the wanted value.
+
+LazyDoc keeps the src, the token table of stepA, and the position of the
+pair of every { [ ] } token. A path lookup walks on the token table, and the
+unneeded subtrees are skipped in one step with the pair positions.
+Only the found value is built with stepC.
+
+	doc, _ := jyp.LazyParse(src)
+	image, err := doc.GetPath("/spec/containers/0/image")
+
+In a LazyDoc path, an array elem can be selected with its index.
+*/
+
+package jyp
+
+import (
+	"errors"
+	"strconv"
+)
+
+type LazyDoc struct {
+	src    []byte
+	tokens tokenElems
+	pairs  []int // for { [ ] } tokens: the position of the pair token. -1 for the other tokens
+}
+
+func LazyParse(srcStr string) (LazyDoc, []error) {
+	return LazyParseBytes([]byte(srcStr))
+}
+
+// the src is not copied, don't modify it while the LazyDoc is used
+func LazyParseBytes(src []byte) (LazyDoc, []error) {
+	tokens := stepA__tokensTableDetect_structuralTokens_strings_L1(src)
+	errorsCollected := stepB__JSON_validation_L1(src, tokens, Options{})
+	if len(errorsCollected) > 0 {
+		return LazyDoc{}, errorsCollected
+	}
+	return LazyDoc{src: src, tokens: tokens, pairs: tokensTable_pairs_detect(tokens)}, errorsCollected
+}
+
+// the token table has to be validated, so every opener has a pair
+func tokensTable_pairs_detect(tokens tokenElems) []int { // TESTED
+	pairs := make([]int, len(tokens))
+	openers := []int{}
+	for pos, token := range tokens {
+		pairs[pos] = -1
+		if token.tokenType == '{' || token.tokenType == '[' {
+			openers = append(openers, pos)
+		} else if token.tokenType == '}' || token.tokenType == ']' {
+			posOpener := openers[len(openers)-1]
+			openers = openers[:len(openers)-1]
+			pairs[posOpener] = pos
+			pairs[pos] = posOpener
+		}
+	}
+	return pairs
+}
+
+// the whole document is built
+func (d LazyDoc) Root() (JSON_value, error) {
+	if len(d.tokens) == 0 {
+		return JSON_value{}, errors.New(errorPrefix + "empty LazyDoc")
+	}
+	return d.value_build(0), nil
+}
+
+func (d LazyDoc) GetPath(keysMerged string) (JSON_value, error) {
+	if len(keysMerged) < 2 {
+		return JSON_value{}, errors.New(errorPrefix + "missing separator and key(s) in merged GetPath")
+	}
+	keys, _ := ObjPath_merged_expand__split_with_first_char(keysMerged)
+	return d.GetPathKeys(keys)
+}
+
+func (d LazyDoc) GetPathKeys(keysEmbedded []string) (JSON_value, error) {
+	posToken, err := d.path_token_find(keysEmbedded)
+	if err != nil {
+		return JSON_value{}, err
+	}
+	return d.value_build(posToken), nil
+}
+
+// the token position of the value in the path
+func (d LazyDoc) path_token_find(keysEmbedded []string) (int, error) {
+	if len(keysEmbedded) < 1 {
+		return 0, errors.New(errorPrefix + "missing object keys (no keys are passed)")
+	}
+	if len(d.tokens) == 0 {
+		return 0, errors.New(errorPrefix + "empty LazyDoc")
+	}
+
+	posNow := 0
+	for _, key := range keysEmbedded {
+		var posNext int
+		var err error
+		if d.tokens[posNow].tokenType == '{' {
+			posNext, err = d.obj_value_find(posNow, key)
+		} else if d.tokens[posNow].tokenType == '[' {
+			posNext, err = d.arr_elem_find(posNow, key)
+		} else {
+			err = errors.New(errorPrefix + key + "-> parent is not object or array, key cannot be used")
+		}
+		if err != nil {
+			return 0, err
+		}
+		posNow = posNext
+	}
+	return posNow, nil
+}
+
+// the position after the value, the next comma or the closer of the container
+func (d LazyDoc) value_end_next(posValue int) int {
+	if d.pairs[posValue] > posValue { // an opener, the subtree is skipped
+		return d.pairs[posValue] + 1
+	}
+	return posValue + 1
+}
+
+// if the key is duplicated, the last one is used, as in JSON_value
+func (d LazyDoc) obj_value_find(posObj int, key string) (int, error) {
+	posFound := -1
+	for pos := posObj + 1; pos < d.pairs[posObj]; {
+		// pos: key, pos+1: colon, pos+2: value
+		if d.key_is_equal(d.tokens[pos], key) {
+			posFound = pos + 2
+		}
+		pos = d.value_end_next(pos+2) + 1 // the comma is skipped
+	}
+	if posFound == -1 {
+		return 0, errors.New(errorPrefix + "unknown object key (key:" + key + ")")
+	}
+	return posFound, nil
+}
+
+func (d LazyDoc) arr_elem_find(posArr int, indexStr string) (int, error) {
+	index, err := strconv.Atoi(indexStr)
+	if err != nil || index < 0 {
+		return 0, errors.New(errorPrefix + "array index is not a non-negative number (" + indexStr + ")")
+	}
+	for pos := posArr + 1; pos < d.pairs[posArr]; index-- {
+		if index == 0 {
+			return pos, nil
+		}
+		pos = d.value_end_next(pos) + 1
+	}
+	return 0, errors.New(errorPrefix + "index (" + indexStr + ") is not in array")
+}
+
+// raw compare if there is no escaping in the key - the key is not decoded
+func (d LazyDoc) key_is_equal(keyToken tokenElem, key string) bool {
+	keyRaw := base__read_sourceCode_section_basedOnTokenPositions(d.src, keyToken, true)
+	for _, b := range keyRaw {
+		if b == '\\' {
+			return stringValueParsing_rawToInterpretedCharacters_L2(keyRaw) == key
+		}
+	}
+	return string(keyRaw) == key
+}
+
+// only the subtree of the wanted token is built
+func (d LazyDoc) value_build(posToken int) JSON_value {
+	value, _ := stepC__JSON_structure_building__L1(d.src, d.tokens, posToken, nil, Options{})
+	return value
+}
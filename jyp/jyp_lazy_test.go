@@ -0,0 +1,76 @@
+/*
+Copyright (c) 2024, Balazs Nyiro, balazs.nyiro.ca@gmail.com
+All rights reserved.
+
+This source code (all file in this repo) is licensed
+under the Apache-2 style license found in the
+LICENSE file in the root directory of this source tree.
+
+*/
+
+package jyp
+
+import "testing"
+
+// go test -v -run Test_LazyParse
+func Test_LazyParse(t *testing.T) {
+	funName := "Test_LazyParse"
+	testName := funName + "_GetPath"
+
+	src := `{"kind": "Deployment",
+		"metadata": {"name": "web", "labels": {"app": "web", "tier": "frontend"}},
+		"spec": {"replicas": 3,
+		         "template": {"spec": {"containers": [{"name": "a", "image": "nginx:1.25"},
+		                                              {"name": "b", "image": "redis:7", "ports": [{"containerPort": 6379}]}]}}},
+		"esc\"aped": "yes"}`
+	doc, errorsCollected := LazyParse(src)
+	compare_int_int(testName, 0, len(errorsCollected), t)
+
+	replicas, err := doc.GetPath("/spec/replicas")
+	compare_bool_bool(testName, true, err == nil, t)
+	compare_int_int(testName, 3, replicas.ValNumberInt, t)
+
+	image, _ := doc.GetPath("/spec/template/spec/containers/1/image")
+	compare_str_str(testName, "redis:7", image.ValRunes, t)
+
+	port, _ := doc.GetPathKeys([]string{"spec", "template", "spec", "containers", "1", "ports", "0", "containerPort"})
+	compare_int_int(testName, 6379, port.ValNumberInt, t)
+
+	labels, _ := doc.GetPath("/metadata/labels")
+	compare_str_str(testName, `{"app":"web","tier":"frontend"}`, labels.Repr(), t)
+
+	escaped, _ := doc.GetPathKeys([]string{`esc"aped`})
+	compare_str_str(testName, "yes", escaped.ValRunes, t)
+
+	testName = funName + "_errors"
+	_, err = doc.GetPath("/spec/missing")
+	compare_bool_bool(testName, true, err != nil, t)
+	_, err = doc.GetPath("/spec/template/spec/containers/2")
+	compare_bool_bool(testName, true, err != nil, t)
+	_, err = doc.GetPath("/spec/template/spec/containers/x")
+	compare_bool_bool(testName, true, err != nil, t)
+	_, err = doc.GetPath("/kind/x")
+	compare_bool_bool(testName, true, err != nil, t)
+
+	testName = funName + "_root"
+	root, _ := doc.Root()
+	compare_str_str(testName, "Deployment", root.ValObject["kind"].ValRunes, t)
+
+	testName = funName + "_invalid_src"
+	_, errorsCollected = LazyParse(`{"a": [1 2]}`)
+	compare_int_int(testName, 1, len(errorsCollected), t)
+}
+
+// go test -v -run Test_tokensTable_pairs_detect
+func Test_tokensTable_pairs_detect(t *testing.T) {
+	funName := "Test_tokensTable_pairs_detect"
+	testName := funName + "_base"
+
+	pairs := tokensTable_pairs_detect(stepA__tokensTableDetect_structuralTokens_strings_L1([]byte(`{"a": [1, {}], "b": 2}`)))
+	// tokens: { "a" : [ 1 , { } ] , "b" : 2 }
+	compare_int_int(testName, 13, pairs[0], t)
+	compare_int_int(testName, 0, pairs[13], t)
+	compare_int_int(testName, 8, pairs[3], t)
+	compare_int_int(testName, 7, pairs[6], t)
+	compare_int_int(testName, -1, pairs[1], t)
+}
@@ -0,0 +1,63 @@
+/*
+Copyright (c) 2024, Balazs Nyiro, balazs.nyiro.ca@gmail.com
+All rights reserved.
+
+This source code (all file in this repo) is licensed
+under the Apache-2 style license found in the
+LICENSE file in the root directory of this source tree.
+
+This module: resource limits for untrusted input.
+
+	root, errorsCollected := jyp.JsonParseWithOptions(requestBody, jyp.Options{Limits: jyp.LimitsDefault()})
+	if len(errorsCollected) > 0 && errors.Is(errorsCollected[0], jyp.ErrLimitExceeded) { ... }
+
+The limits are checked in the validation (stepB), before the structure building,
+so a too big or too deep src is rejected without building the tree.
+With MaxErrors, a src full of problems is not validated till its end:
+the first MaxErrors errors are returned.
+The Decoder and the NdjsonReader check them while the stream is read (SetLimits).
+
+A 0 field means: no limit. The zero Limits is the default of Options, for compatibility.
+*/
+
+package jyp
+
+import "strconv"
+
+type Limits struct {
+	MaxInputBytes    int // the size of the src. Decoder: the size of one value, NdjsonReader: the size of one line
+	MaxDepth         int // the nesting level of the containers: [[1]] is 2
+	MaxStringBytes   int // the size of a string or key in the src, without the quotes. An escape like \n is 2 bytes
+	MaxArrayLen      int // num of elems in one array
+	MaxObjectMembers int // num of keys in one object
+	MaxErrors        int // num of the reported errors: the validation is stopped after this many problems
+}
+
+// limits for http request bodies and similar, untrusted input
+func LimitsDefault() Limits {
+	return Limits{
+		MaxInputBytes:    10 << 20,
+		MaxDepth:         512,
+		MaxStringBytes:   1 << 20,
+		MaxArrayLen:      1 << 20,
+		MaxObjectMembers: 1 << 16,
+		MaxErrors:        100,
+	}
+}
+
+// a 0 limit means: no limit
+func limit_is_exceeded(limit, value int) bool { // TESTED
+	return limit > 0 && value > limit
+}
+
+// MaxErrors: the errors after the limit are dropped
+func limit_errors_cut(errorsCollected []error, maxErrors int) []error { // TESTED
+	if limit_is_exceeded(maxErrors, len(errorsCollected)) {
+		return errorsCollected[:maxErrors]
+	}
+	return errorsCollected
+}
+
+func limit_exceeded_msg(limitName string, limit int) string { // TESTED
+	return "resource limit exceeded: " + limitName + " is " + strconv.Itoa(limit)
+}
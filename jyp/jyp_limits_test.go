@@ -0,0 +1,195 @@
+/*
+Copyright (c) 2024, Balazs Nyiro, balazs.nyiro.ca@gmail.com
+All rights reserved.
+
+This source code (all file in this repo) is licensed
+under the Apache-2 style license found in the
+LICENSE file in the root directory of this source tree.
+
+*/
+
+package jyp
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// go test -v -run Test_JsonParse_Limits
+func Test_JsonParse_Limits(t *testing.T) {
+	funName := "Test_JsonParse_Limits"
+
+	testName := funName + "_exceeded"
+	srcLimits := map[string]Limits{
+		`[1, 2, 3]`:                       {MaxInputBytes: 8},
+		`{"a": [[1]]}`:                    {MaxDepth: 2},
+		`["abcd"]`:                        {MaxStringBytes: 3},
+		`{"abcd": 1}`:                     {MaxStringBytes: 3},
+		`[[1, 2], [1, 2, 3]]`:             {MaxArrayLen: 2},
+		`{"a": 1, "b": {"c": 1, "d": 2}}`: {MaxObjectMembers: 1},
+	}
+	for src, limits := range srcLimits {
+		root, errorsCollected := JsonParseWithOptions(src, Options{Limits: limits})
+		compare_int_int(testName+" "+src, 1, len(errorsCollected), t)
+		compare_bool_bool(testName+" "+src, true, errors.Is(errorsCollected[0], ErrLimitExceeded), t)
+		compare_rune_rune(testName+" "+src, 0, root.ValType, t)
+	}
+
+	testName = funName + "_position"
+	_, errorsCollected := JsonParseWithOptions("[1,\n [2, [3]]]", Options{Limits: Limits{MaxDepth: 2}})
+	compare_int_int(testName, 2, errorsCollected[0].(ParseError).Line, t)
+	compare_int_int(testName, 6, errorsCollected[0].(ParseError).Column, t)
+
+	testName = funName + "_not_exceeded"
+	src := `{"a": [1, 2], "bc": "xyz"}`
+	root, errorsCollected := JsonParseWithOptions(src, Options{Limits: Limits{MaxInputBytes: len(src), MaxDepth: 2, MaxStringBytes: 3, MaxArrayLen: 2, MaxObjectMembers: 2}})
+	compare_int_int(testName, 0, len(errorsCollected), t)
+	compare_str_str(testName, `{"a":[1,2],"bc":"xyz"}`, root.Repr(), t)
+
+	testName = funName + "_relaxed_identifier_key"
+	_, errorsCollected = JsonParseWithOptions(`{abcd: 1}`, Options{Relaxed: true, Limits: Limits{MaxStringBytes: 3}})
+	compare_bool_bool(testName, true, len(errorsCollected) == 1 && errors.Is(errorsCollected[0], ErrLimitExceeded), t)
+}
+
+// go test -v -run Test_JsonParse_deep_nesting
+func Test_JsonParse_deep_nesting(t *testing.T) {
+	funName := "Test_JsonParse_deep_nesting"
+	depth := 200000
+	src := strings.Repeat("[", depth) + strings.Repeat("]", depth)
+
+	testName := funName + "_without_limits" // the structure building is not recursive
+	root, errorsCollected := JsonParse(src)
+	compare_int_int(testName, 0, len(errorsCollected), t)
+	depthBuilt := 0
+	for value := root; len(value.ValArray) > 0; value = value.ValArray[0] {
+		depthBuilt++
+	}
+	compare_int_int(testName, depth-1, depthBuilt, t)
+
+	testName = funName + "_default_limits"
+	_, errorsCollected = JsonParseWithOptions(src, Options{Limits: LimitsDefault()})
+	compare_int_int(testName, 1, len(errorsCollected), t)
+	compare_bool_bool(testName, true, errors.Is(errorsCollected[0], ErrLimitExceeded), t)
+}
+
+// go test -v -run Test_JsonParse_MaxErrors
+func Test_JsonParse_MaxErrors(t *testing.T) {
+	funName := "Test_JsonParse_MaxErrors"
+	src := "[" + strings.Repeat("x,", 1<<20) + "1]" // 2Mb, every elem is invalid
+
+	testName := funName + "_default_limits"
+	timeStart := time.Now()
+	_, errorsCollected := JsonParseWithOptions(src, Options{Limits: LimitsDefault()})
+	compare_bool_bool(testName, true, time.Since(timeStart) < 5*time.Second, t)
+	compare_int_int(testName, LimitsDefault().MaxErrors, len(errorsCollected), t)
+	compare_bool_bool(testName, true, errors.Is(errorsCollected[0], ErrInvalidLiteral), t)
+
+	testName = funName + "_unclosed_containers" // the errors after the root are cut, too
+	_, errorsCollected = JsonParseWithOptions("[[[[x", Options{Limits: Limits{MaxErrors: 2}})
+	compare_int_int(testName, 2, len(errorsCollected), t)
+	compare_bool_bool(testName, true, errors.Is(errorsCollected[1], ErrUnclosedContainer), t)
+
+	testName = funName + "_no_limit"
+	_, errorsCollected = JsonParse("[[[[x")
+	compare_int_int(testName, 5, len(errorsCollected), t)
+}
+
+// go test -v -run Test_Decoder_Limits
+func Test_Decoder_Limits(t *testing.T) {
+	funName := "Test_Decoder_Limits"
+
+	testName := funName + "_decode_depth" // the value is rejected while it is read
+	decoder := NewDecoder(strings.NewReader(strings.Repeat("[", 100000)))
+	decoder.SetLimits(Limits{MaxDepth: 64})
+	_, errorsCollected := decoder.Decode()
+	compare_bool_bool(testName, true, errors.Is(errorsCollected[0], ErrLimitExceeded), t)
+	compare_bool_bool(testName, true, len(decoder.valueBuf) < 10000, t)
+	compare_int_int(testName, 65, errorsCollected[0].(ParseError).Column, t) // the opener that is too deep, not the end of the read chunk
+	compare_int_int(testName, 0, len(decoder.valueBuf), t)                   // the containers are not buffered
+	_, errorsCollected = decoder.Decode()                                    // the stream is stopped
+	compare_bool_bool(testName, true, errors.Is(errorsCollected[0], ErrLimitExceeded), t)
+
+	testName = funName + "_decode_size"
+	decoder = NewDecoder(strings.NewReader(`[1] [1,2,3,4]`))
+	decoder.SetLimits(Limits{MaxInputBytes: 5})
+	value, errorsCollected := decoder.Decode()
+	compare_int_int(testName, 0, len(errorsCollected), t)
+	compare_str_str(testName, "[1]", value.Repr(), t)
+	_, errorsCollected = decoder.Decode()
+	compare_bool_bool(testName, true, errors.Is(errorsCollected[0], ErrLimitExceeded), t)
+
+	testName = funName + "_token"
+	srcLimits := map[string]Limits{
+		`[[[1]]]`:                  {MaxDepth: 2},
+		`[1, 2, 3]`:                {MaxArrayLen: 2},
+		`{"a": 1, "b": 2, "c": 3}`: {MaxObjectMembers: 2},
+		`{"abcd": 1}`:              {MaxStringBytes: 3},
+		`["abcd"]`:                 {MaxStringBytes: 3},
+	}
+	for src, limits := range srcLimits {
+		decoder = NewDecoder(strings.NewReader(src))
+		decoder.SetLimits(limits)
+		var err error
+		for err == nil {
+			_, err = decoder.Token()
+		}
+		compare_bool_bool(testName+" "+src, true, errors.Is(err, ErrLimitExceeded), t)
+	}
+
+	testName = funName + "_token_depth_position" // Decode inside the containers of Token()
+	decoder = NewDecoder(strings.NewReader(`[{"a": [[1]]}]`))
+	decoder.SetLimits(Limits{MaxDepth: 3})
+	for pos := 0; pos < 3; pos++ { // [ { "a"
+		_, _ = decoder.Token()
+	}
+	_, errorsCollected = decoder.Decode()
+	compare_bool_bool(testName, true, errors.Is(errorsCollected[0], ErrLimitExceeded), t)
+	compare_int_int(testName, 9, errorsCollected[0].(ParseError).Column, t)
+
+	testName = funName + "_token_not_exceeded"
+	decoder = NewDecoder(strings.NewReader(`{"a": [1, 2], "b": {"c": "xyz"}}`))
+	decoder.SetLimits(Limits{MaxDepth: 2, MaxArrayLen: 2, MaxObjectMembers: 2, MaxStringBytes: 3})
+	var err error
+	for err == nil {
+		_, err = decoder.Token()
+	}
+	compare_bool_bool(testName, true, err == io.EOF, t)
+}
+
+// go test -v -run Test_NdjsonReader_Limits
+func Test_NdjsonReader_Limits(t *testing.T) {
+	testName := "Test_NdjsonReader_Limits"
+
+	reader := NewNdjsonReader(strings.NewReader(`{"a": 1}`+"\n"+`["`+strings.Repeat("x", 10000)+`"]`+"\n"+`[2]`), NdjsonErrorsCollect)
+	reader.SetLimits(Limits{MaxInputBytes: 100})
+	record, err := reader.Read()
+	compare_bool_bool(testName, true, err == nil, t)
+	compare_int_int(testName, 1, record.Line, t)
+	record, err = reader.Read() // the too long line is skipped
+	compare_bool_bool(testName, true, err == nil, t)
+	compare_int_int(testName, 3, record.Line, t)
+	compare_str_str(testName, "[2]", record.Value.Repr(), t)
+	compare_int_int(testName, 1, len(reader.Errors()), t)
+	compare_bool_bool(testName, true, errors.Is(reader.Errors()[0], ErrLimitExceeded), t)
+	compare_int_int(testName, 2, reader.Errors()[0].(ParseError).Line, t)
+	_, err = reader.Read()
+	compare_bool_bool(testName, true, err == io.EOF, t)
+}
+
+// go test -v -run Test_limit_is_exceeded
+func Test_limit_is_exceeded(t *testing.T) {
+	testName := "Test_limit_is_exceeded"
+	compare_bool_bool(testName, false, limit_is_exceeded(0, 1000), t)
+	compare_bool_bool(testName, false, limit_is_exceeded(3, 3), t)
+	compare_bool_bool(testName, true, limit_is_exceeded(3, 4), t)
+	compare_str_str(testName, "resource limit exceeded: MaxDepth is 512", limit_exceeded_msg("MaxDepth", 512), t)
+
+	testName = "Test_limit_errors_cut"
+	errorsCollected := []error{ErrMissingComma, ErrMissingColon, ErrMissingValue}
+	compare_int_int(testName, 2, len(limit_errors_cut(errorsCollected, 2)), t)
+	compare_int_int(testName, 3, len(limit_errors_cut(errorsCollected, 3)), t)
+	compare_int_int(testName, 3, len(limit_errors_cut(errorsCollected, 0)), t)
+}
@@ -0,0 +1,182 @@
+/*
+Copyright (c) 2024, Balazs Nyiro, balazs.nyiro.ca@gmail.com
+All rights reserved.
+
+This source code (all file in this repo) is licensed
+under the Apache-2 style license found in the
+LICENSE file in the root directory of this source tree.
+
+This module: JSON Lines / NDJSON (newline delimited json) reader and writer.
+
+Every line is one separated json value, for example in log files:
+
+	reader := jyp.NewNdjsonReader(file, jyp.NdjsonErrorsCollect)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF { break }
+		... record.Line, record.Value
+	}
+	problems := reader.Errors()
+
+Blank lines are skipped, CRLF line endings are accepted.
+The positions in the errors are stream positions (line num, offset).
+*/
+
+package jyp
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"unicode/utf8"
+)
+
+// what happens if a line is not a valid json value
+type NdjsonErrorPolicy int
+
+const (
+	NdjsonErrorsSkip    NdjsonErrorPolicy = iota // the broken line is skipped, the errors are dropped
+	NdjsonErrorsCollect                          // the broken line is skipped, the errors are saved, see Errors()
+	NdjsonErrorsStop                             // Read() returns the first error, and the reading is stopped
+)
+
+type NdjsonRecord struct {
+	Line  int // 1 based line num in the stream
+	Value JSON_value
+}
+
+type NdjsonReader struct {
+	reader          *bufio.Reader
+	policy          NdjsonErrorPolicy
+	opts            Options // see SetOptions, SetLimits
+	errorsCollected []error
+	errStop         error
+
+	line    int // the num of the last read line
+	posRune int // stream positions of the next line
+	posByte int
+}
+
+func NewNdjsonReader(r io.Reader, policy NdjsonErrorPolicy) *NdjsonReader {
+	return &NdjsonReader{reader: bufio.NewReader(r), policy: policy}
+}
+
+// the limits are checked in every line, MaxInputBytes is the size of one line.
+// A too long line is not buffered, it is handled as a broken line (see the error policy)
+func (r *NdjsonReader) SetLimits(limits Limits) {
+	r.opts.Limits = limits
+}
+
+// the options of the line parsing: DuplicateKeys, NumbersRaw, Positions...
+// opts.Limits is used as in SetLimits. The Positions are counted from the start of the line
+func (r *NdjsonReader) SetOptions(opts Options) {
+	r.opts = opts
+}
+
+// Read returns the next value of the stream. At the end of the stream, the error is io.EOF
+func (r *NdjsonReader) Read() (NdjsonRecord, error) {
+	if r.errStop != nil {
+		return NdjsonRecord{}, r.errStop
+	}
+
+	for {
+		line, numOfRunes, numOfBytes, errRead := r.line_read()
+		if errRead != nil && errRead != io.EOF {
+			return NdjsonRecord{}, errRead
+		}
+		if numOfBytes == 0 && errRead == io.EOF {
+			return NdjsonRecord{}, io.EOF
+		}
+
+		r.line++
+		posRuneStart, posByteStart := r.posRune, r.posByte
+		r.posRune += numOfRunes
+		r.posByte += numOfBytes
+
+		line = bytes.TrimSuffix(line, []byte("\n"))
+		line = bytes.TrimSuffix(line, []byte("\r"))
+		if ndjson_line_is_blank(line) {
+			continue
+		}
+
+		value, errorsCollected := JsonParseBytesWithOptions(line, r.opts)
+		if len(errorsCollected) == 0 {
+			return NdjsonRecord{Line: r.line, Value: value}, nil
+		}
+
+		for pos, err := range errorsCollected {
+			if parseErr, isParseErr := err.(ParseError); isParseErr {
+				errorsCollected[pos] = parseErr.shifted(posRuneStart, posByteStart, r.line, 1)
+			}
+		}
+		if r.policy == NdjsonErrorsStop {
+			r.errStop = errorsCollected[0]
+			return NdjsonRecord{}, r.errStop
+		}
+		if r.policy == NdjsonErrorsCollect {
+			r.errorsCollected = append(r.errorsCollected, errorsCollected...)
+		}
+	}
+}
+
+// the next line, with the newline. With Limits.MaxInputBytes, a longer line is not collected:
+// only its beginning is returned (so the parser reports the limit), the rest is read and dropped.
+// The num of runes and bytes are counted in the whole line
+func (r *NdjsonReader) line_read() ([]byte, int, int, error) {
+	if r.opts.Limits.MaxInputBytes < 1 {
+		line, err := r.reader.ReadBytes('\n')
+		return line, utf8.RuneCount(line), len(line), err
+	}
+
+	line := []byte{}
+	numOfRunes, numOfBytes := 0, 0
+	for {
+		chunk, err := r.reader.ReadSlice('\n')
+		for _, b := range chunk {
+			if b&0xC0 != 0x80 { // not an utf8 continuation byte, a chunk can end inside a rune
+				numOfRunes++
+			}
+		}
+		numOfBytes += len(chunk)
+		if len(line) <= r.opts.Limits.MaxInputBytes {
+			line = append(line, chunk...)
+		}
+		if err != bufio.ErrBufferFull {
+			return line, numOfRunes, numOfBytes, err
+		}
+	}
+}
+
+// the errors of the skipped lines, with NdjsonErrorsCollect policy
+func (r *NdjsonReader) Errors() []error {
+	return r.errorsCollected
+}
+
+func ndjson_line_is_blank(line []byte) bool { // TESTED
+	for _, b := range line {
+		if !base__is_whitespace_byte(b) {
+			return false
+		}
+	}
+	return true
+}
+
+////////////////////////////////////////////////////////////////////////////////////
+
+// NdjsonWriter writes one compact value per line, with the Encoder
+type NdjsonWriter struct {
+	encoder *Encoder
+}
+
+func NewNdjsonWriter(w io.Writer) NdjsonWriter {
+	return NdjsonWriter{encoder: NewEncoder(w)}
+}
+
+// the zero JSON_value{} has no JSON form: it would be an empty line, that is skipped by the reader
+func (w NdjsonWriter) Write(value JSON_value) error {
+	if value.ValType == 0 {
+		return errors.New(errorPrefix + "the value has no type (zero JSON_value), it cannot be written")
+	}
+	return w.encoder.Encode(value)
+}
@@ -0,0 +1,132 @@
+/*
+Copyright (c) 2024, Balazs Nyiro, balazs.nyiro.ca@gmail.com
+All rights reserved.
+
+This source code (all file in this repo) is licensed
+under the Apache-2 style license found in the
+LICENSE file in the root directory of this source tree.
+
+*/
+
+package jyp
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+var srcNdjson = "{\"level\": \"info\", \"msg\": \"start\"}\r\n" +
+	"\n" +
+	"   \r\n" +
+	"{\"level\": \"warn\", \"msg\": \"slow\", \"ms\": 1200}\n" +
+	"{\"level\": \"error\" \"msg\": \"broken\"}\n" +
+	"[1, 2, 3]\r\n" +
+	"{\"level\": \"info\", \"msg\": \"stop\"}"
+
+// go test -v -run Test_NdjsonReader
+func Test_NdjsonReader(t *testing.T) {
+	funName := "Test_NdjsonReader"
+
+	testName := funName + "_collect"
+	reader := NewNdjsonReader(strings.NewReader(srcNdjson), NdjsonErrorsCollect)
+	records := ndjson_read_all(reader, t)
+	compare_int_int(testName, 4, len(records), t)
+	compare_int_int(testName, 1, records[0].Line, t)
+	compare_str_str(testName, "start", records[0].Value.ValObject["msg"].ValRunes, t)
+	compare_int_int(testName, 4, records[1].Line, t)
+	compare_int_int(testName, 1200, records[1].Value.ValObject["ms"].ValNumberInt, t)
+	compare_int_int(testName, 6, records[2].Line, t)
+	compare_str_str(testName, "[1,2,3]", records[2].Value.Repr(), t)
+	compare_int_int(testName, 7, records[3].Line, t)
+
+	compare_int_int(testName, 1, len(reader.Errors()), t)
+	parseErr := reader.Errors()[0].(ParseError)
+	compare_bool_bool(testName, true, errors.Is(parseErr, ErrMissingComma), t)
+	compare_int_int(testName, 5, parseErr.Line, t)
+	compare_int_int(testName, 19, parseErr.Column, t)
+	compare_int_int(testName, strings.Index(srcNdjson, `"msg": "broken"`), parseErr.ByteOffset, t)
+
+	testName = funName + "_skip"
+	reader = NewNdjsonReader(strings.NewReader(srcNdjson), NdjsonErrorsSkip)
+	records = ndjson_read_all(reader, t)
+	compare_int_int(testName, 4, len(records), t)
+	compare_int_int(testName, 0, len(reader.Errors()), t)
+
+	testName = funName + "_stop"
+	reader = NewNdjsonReader(strings.NewReader(srcNdjson), NdjsonErrorsStop)
+	for lineNum := 0; lineNum < 2; lineNum++ {
+		_, err := reader.Read()
+		compare_bool_bool(testName, true, err == nil, t)
+	}
+	_, err := reader.Read()
+	compare_bool_bool(testName, true, errors.Is(err, ErrMissingComma), t)
+	_, err = reader.Read() // the error is sticky
+	compare_bool_bool(testName, true, errors.Is(err, ErrMissingComma), t)
+
+	testName = funName + "_options"
+	reader = NewNdjsonReader(strings.NewReader(`{"a": 1}`+"\n"+`{"a": 1, "a": 2}`), NdjsonErrorsStop)
+	reader.SetOptions(Options{DuplicateKeys: DuplicateKeysError})
+	_, err = reader.Read()
+	compare_bool_bool(testName, true, err == nil, t)
+	_, err = reader.Read()
+	compare_bool_bool(testName, true, errors.Is(err, ErrDuplicateKey), t)
+	compare_int_int(testName, 2, err.(ParseError).Line, t)
+
+	testName = funName + "_empty"
+	records = ndjson_read_all(NewNdjsonReader(strings.NewReader("\n\r\n"), NdjsonErrorsStop), t)
+	compare_int_int(testName, 0, len(records), t)
+}
+
+// go test -v -run Test_NdjsonWriter
+func Test_NdjsonWriter(t *testing.T) {
+	funName := "Test_NdjsonWriter"
+	testName := funName + "_roundtrip"
+
+	out := strings.Builder{}
+	writer := NewNdjsonWriter(&out)
+	for _, record := range ndjson_read_all(NewNdjsonReader(strings.NewReader(srcNdjson), NdjsonErrorsSkip), t) {
+		compare_bool_bool(testName, true, writer.Write(record.Value) == nil, t)
+	}
+	wanted := `{"level":"info","msg":"start"}
+{"level":"warn","ms":1200,"msg":"slow"}
+[1,2,3]
+{"level":"info","msg":"stop"}
+`
+	compare_str_str(testName, wanted, out.String(), t)
+
+	testName = funName + "_multiline_string"
+	out.Reset()
+	writer.Write(NewStr("line1\nline2"))
+	compare_str_str(testName, `"line1\nline2"`+"\n", out.String(), t)
+
+	testName = funName + "_zero_value" // not an empty line, that would be skipped by the reader
+	out.Reset()
+	compare_bool_bool(testName, true, writer.Write(JSON_value{}) != nil, t)
+	compare_str_str(testName, "", out.String(), t)
+	compare_bool_bool(testName, true, writer.Write(NewArr(JSON_value{ValType: '?'})) == nil, t) // a broken node is null
+	compare_str_str(testName, "[null]\n", out.String(), t)
+}
+
+// go test -v -run Test_ndjson_line_is_blank
+func Test_ndjson_line_is_blank(t *testing.T) {
+	testName := "Test_ndjson_line_is_blank"
+	compare_bool_bool(testName, true, ndjson_line_is_blank([]byte{}), t)
+	compare_bool_bool(testName, true, ndjson_line_is_blank([]byte(" \t\r")), t)
+	compare_bool_bool(testName, false, ndjson_line_is_blank([]byte(" 1 ")), t)
+}
+
+func ndjson_read_all(reader *NdjsonReader, t *testing.T) []NdjsonRecord {
+	records := []NdjsonRecord{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return records
+		}
+		if err != nil {
+			t.Fatalf("\nndjson read error: %s", err)
+		}
+		records = append(records, record)
+	}
+}
@@ -0,0 +1,175 @@
+/*
+Copyright (c) 2024, Balazs Nyiro, balazs.nyiro.ca@gmail.com
+All rights reserved.
+
+This source code (all file in this repo) is licensed
+under the Apache-2 style license found in the
+LICENSE file in the root directory of this source tree.
+
+This module: raw number literals and arbitrary-precision number accessors.
+
+	root, _ := jyp.JsonParseWithOptions(src, jyp.Options{NumbersRaw: true})
+	id, _ := root.ValObject["id"].NumberBigInt()        // 12345678901234567890, exactly
+	amount := root.ValObject["amount"].ValNumberRaw     // "0.10", as it was in the src
+
+With Options.NumbersRaw, every number keeps its literal text in ValNumberRaw, and Repr()
+writes the literal back unchanged. The ValNumberInt/ValNumberFloat fields are filled as before.
+A literal out of the float64 range (1e400) is accepted only in this mode: its ValNumberFloat is +-Inf,
+NumberBigFloat() gives back the value. Without NumbersRaw it is an ErrInvalidLiteral.
+
+An integer literal that doesn't fit into int is stored as a float (as before), but its literal
+is kept in ValNumberRaw in every mode, so the precision is never lost silently: NumberIntOverflow()
+reports these values, NumberBigInt() gives back the exact value.
+*/
+
+package jyp
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"strconv"
+)
+
+// a number value with a literal, that is written back by Repr()
+func NewNumRaw(literal string) (JSON_value, error) {
+	if !numberValueParsing_literal_is_json_L2(literal) {
+		return JSON_value{}, errors.New(errorPrefix + "not a JSON number literal: " + literal)
+	}
+	value, _ := numberValueParsing_textToNumber_raw_L2(literal)
+	return value, nil
+}
+
+// the literal is kept in ValNumberRaw. A JSON literal out of the float64 range (1e400)
+// is a number, too: its ValNumberFloat is +-Inf, the exact value is in the literal
+func numberValueParsing_textToNumber_raw_L2(literal string) (JSON_value, bool) { // TESTED
+	value, isNumber := numberValueParsing_textToNumber_L2(literal)
+	if !numberValueParsing_literal_is_json_L2(literal) {
+		return value, isNumber
+	}
+	if !isNumber {
+		numFloat, _ := strconv.ParseFloat(literal, 64) // +-Inf, with ErrRange
+		value = NewNumFloat(numFloat)
+	}
+	value.ValNumberRaw = literal
+	return value, true
+}
+
+// the number literal is accepted in the validation. Out of the float64 range only with NumbersRaw:
+// without the literal the value would be lost
+func numberValueParsing_literal_is_valid_L2(textInSrc string, opts Options) bool { // TESTED
+	if opts.NumbersRaw && numberValueParsing_literal_is_json_L2(textInSrc) {
+		return true
+	}
+	if opts.Relaxed {
+		_, isNumber := numberValueParsing_textToNumber_relaxed_L2(textInSrc)
+		return isNumber
+	}
+	_, isNumber := numberValueParsing_textToNumber_L2(textInSrc)
+	return isNumber && numberValueParsing_literal_is_json_L2(textInSrc)
+}
+
+// -?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?   the number grammar of the JSON spec
+func numberValueParsing_literal_is_json_L2(text string) bool { // TESTED
+	pos := 0
+	digitsRead := func() int {
+		posStart := pos
+		for pos < len(text) && text[pos] >= '0' && text[pos] <= '9' {
+			pos++
+		}
+		return pos - posStart
+	}
+
+	if pos < len(text) && text[pos] == '-' {
+		pos++
+	}
+	if pos < len(text) && text[pos] == '0' {
+		pos++
+	} else if digitsRead() == 0 {
+		return false
+	}
+	if pos < len(text) && text[pos] == '.' {
+		pos++
+		if digitsRead() == 0 {
+			return false
+		}
+	}
+	if pos < len(text) && (text[pos] == 'e' || text[pos] == 'E') {
+		pos++
+		if pos < len(text) && (text[pos] == '+' || text[pos] == '-') {
+			pos++
+		}
+		if digitsRead() == 0 {
+			return false
+		}
+	}
+	return pos == len(text)
+}
+
+// only digits with an optional sign, there is no fraction or exponent
+func numberValueParsing_literal_is_integer_L2(text string) bool { // TESTED
+	if len(text) > 0 && (text[0] == '-' || text[0] == '+') {
+		text = text[1:]
+	}
+	for pos := 0; pos < len(text); pos++ {
+		if text[pos] < '0' || text[pos] > '9' {
+			return false
+		}
+	}
+	return len(text) > 0
+}
+
+// an integer literal in the src, that was too big for int
+func (v JSON_value) NumberIntOverflow() bool {
+	if v.ValType != 'F' || !numberValueParsing_literal_is_integer_L2(v.ValNumberRaw) {
+		return false
+	}
+	_, err := strconv.Atoi(v.ValNumberRaw)
+	return errors.Is(err, strconv.ErrRange)
+}
+
+// the exact integer value. A float is accepted if it has no fraction: 1e3 or 2.0
+func (v JSON_value) NumberBigInt() (*big.Int, error) {
+	if v.ValType != 'I' && v.ValType != 'F' {
+		return nil, errors.New(errorPrefix + "the value is not a number")
+	}
+	if v.ValNumberRaw == "" && v.ValType == 'I' {
+		return big.NewInt(int64(v.ValNumberInt)), nil
+	}
+	if numberValueParsing_literal_is_integer_L2(v.ValNumberRaw) {
+		num, _ := new(big.Int).SetString(v.ValNumberRaw, 10)
+		return num, nil
+	}
+
+	numFloat, err := v.NumberBigFloat()
+	if err != nil {
+		return nil, err
+	}
+	if !numFloat.IsInt() {
+		return nil, errors.New(errorPrefix + "the number has fraction, it is not an integer")
+	}
+	num, _ := numFloat.Int(nil)
+	return num, nil
+}
+
+// with a raw literal, the precision is big enough for every digit of the literal
+func (v JSON_value) NumberBigFloat() (*big.Float, error) {
+	if v.ValType != 'I' && v.ValType != 'F' {
+		return nil, errors.New(errorPrefix + "the value is not a number")
+	}
+	if v.ValNumberRaw != "" {
+		precision := uint(64 + 4*len(v.ValNumberRaw)) // one decimal digit is less than 4 bits
+		num, _, err := big.ParseFloat(v.ValNumberRaw, 10, precision, big.ToNearestEven)
+		if err != nil {
+			return nil, errors.New(errorPrefix + "invalid number literal: " + v.ValNumberRaw)
+		}
+		return num, nil
+	}
+	if v.ValType == 'I' {
+		return new(big.Float).SetInt64(int64(v.ValNumberInt)), nil
+	}
+	if math.IsNaN(v.ValNumberFloat) || math.IsInf(v.ValNumberFloat, 0) {
+		return nil, errors.New(errorPrefix + "NaN and Infinity are not finite numbers")
+	}
+	return big.NewFloat(v.ValNumberFloat), nil
+}
@@ -0,0 +1,136 @@
+/*
+Copyright (c) 2024, Balazs Nyiro, balazs.nyiro.ca@gmail.com
+All rights reserved.
+
+This source code (all file in this repo) is licensed
+under the Apache-2 style license found in the
+LICENSE file in the root directory of this source tree.
+
+*/
+
+package jyp
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// go test -v -run Test_JsonParse_NumbersRaw
+func Test_JsonParse_NumbersRaw(t *testing.T) {
+	funName := "Test_JsonParse_NumbersRaw"
+
+	testName := funName + "_repr_unchanged"
+	src := `{"amount":0.10,"big":1.5E+2,"id":12345678901234567890,"neg":-0,"small":7}`
+	root, errorsCollected := JsonParseWithOptions(src, Options{NumbersRaw: true})
+	compare_int_int(testName, 0, len(errorsCollected), t)
+	compare_str_str(testName, src, root.Repr(), t)
+	compare_str_str(testName, "0.10", root.ValObject["amount"].ValNumberRaw, t)
+	compare_int_int(testName, 7, root.ValObject["small"].ValNumberInt, t)
+	compare_flt_flt(testName, 150, root.ValObject["big"].ValNumberFloat, t)
+
+	testName = funName + "_big_int"
+	id := root.ValObject["id"]
+	compare_bool_bool(testName, true, id.NumberIntOverflow(), t)
+	idBig, err := id.NumberBigInt()
+	compare_bool_bool(testName, true, err == nil, t)
+	compare_str_str(testName, "12345678901234567890", idBig.String(), t)
+	bigFromFloat, err := root.ValObject["big"].NumberBigInt()
+	compare_bool_bool(testName, true, err == nil, t)
+	compare_str_str(testName, "150", bigFromFloat.String(), t)
+	_, err = root.ValObject["amount"].NumberBigInt()
+	compare_bool_bool(testName, true, err != nil, t)
+
+	testName = funName + "_big_float"
+	amount, err := root.ValObject["amount"].NumberBigFloat()
+	compare_bool_bool(testName, true, err == nil, t)
+	compare_str_str(testName, "0.1000000000000000000000000", amount.Text('f', 25), t)
+
+	testName = funName + "_overflow_without_raw_mode"
+	root, errorsCollected = JsonParse(`[12345678901234567890, 9223372036854775807, 1e30]`)
+	compare_int_int(testName, 0, len(errorsCollected), t)
+	compare_str_str(testName, `[12345678901234567890,9223372036854775807,1000000000000000000000000000000]`, root.Repr(), t)
+	compare_bool_bool(testName, true, root.ValArray[0].NumberIntOverflow(), t)
+	compare_bool_bool(testName, false, root.ValArray[1].NumberIntOverflow(), t)
+	compare_bool_bool(testName, false, root.ValArray[2].NumberIntOverflow(), t)
+	compare_rune_rune(testName, 'I', root.ValArray[1].ValType, t)
+
+	testName = funName + "_out_of_float64_range" // the literal is kept, the value is read with big.Float
+	src = `[1e400,-2.5E+999,1e-400]`
+	for _, opts := range []Options{{NumbersRaw: true}, {NumbersRaw: true, Relaxed: true}, {NumbersRaw: true, Recover: true}} {
+		root, errorsCollected = JsonParseWithOptions(src, opts)
+		compare_int_int(testName, 0, len(errorsCollected), t)
+		compare_str_str(testName, src, root.Repr(), t)
+	}
+	compare_bool_bool(testName, true, math.IsInf(root.ValArray[0].ValNumberFloat, 1), t)
+	compare_bool_bool(testName, true, math.IsInf(root.ValArray[1].ValNumberFloat, -1), t)
+	numBig, err := root.ValArray[0].NumberBigFloat()
+	compare_bool_bool(testName, true, err == nil, t)
+	compare_str_str(testName, "1e+400", numBig.Text('g', 10), t)
+	decoder := NewDecoder(strings.NewReader(src))
+	decoder.SetOptions(Options{NumbersRaw: true})
+	root, errorsCollected = decoder.Decode()
+	compare_int_int(testName, 0, len(errorsCollected), t)
+	compare_str_str(testName, src, root.Repr(), t)
+
+	testName = funName + "_relaxed_literals_are_not_raw"
+	root, errorsCollected = JsonParseWithOptions(`[0x10, +1, 2]`, Options{Relaxed: true, NumbersRaw: true})
+	compare_int_int(testName, 0, len(errorsCollected), t)
+	compare_str_str(testName, `[16,1,2]`, root.Repr(), t)
+}
+
+// go test -v -run Test_NewNumRaw
+func Test_NewNumRaw(t *testing.T) {
+	funName := "Test_NewNumRaw"
+	testName := funName + "_base"
+
+	value, err := NewNumRaw("99999999999999999999.25")
+	compare_bool_bool(testName, true, err == nil, t)
+	compare_rune_rune(testName, 'F', value.ValType, t)
+	obj := NewObj()
+	obj.AddKeyVal("price", value)
+	compare_str_str(testName, `{"price":99999999999999999999.25}`, obj.Repr(), t)
+
+	_, err = NewNumRaw("1.")
+	compare_bool_bool(testName, true, err != nil, t)
+
+	testName = funName + "_out_of_float64_range"
+	value, err = NewNumRaw("-1e400")
+	compare_bool_bool(testName, true, err == nil, t)
+	compare_str_str(testName, "-1e400", value.Repr(), t)
+	compare_bool_bool(testName, true, math.IsInf(value.ValNumberFloat, -1), t)
+	value, isNumber := numberValueParsing_textToNumber_raw_L2("0x10") // not a JSON literal, not kept
+	compare_bool_bool(testName, false, isNumber, t)
+	compare_str_str(testName, "", value.ValNumberRaw, t)
+	compare_bool_bool(testName, false, numberValueParsing_literal_is_valid_L2("1e400", Options{}), t)
+	compare_bool_bool(testName, true, numberValueParsing_literal_is_valid_L2("1e400", Options{NumbersRaw: true}), t)
+	compare_bool_bool(testName, true, numberValueParsing_literal_is_valid_L2("0x10", Options{Relaxed: true}), t)
+
+	testName = funName + "_accessors_without_raw"
+	intBig, _ := NewNumInt(-42).NumberBigInt()
+	compare_str_str(testName, "-42", intBig.String(), t)
+	_, err = NewNumFloat(math.Inf(1)).NumberBigFloat()
+	compare_bool_bool(testName, true, err != nil, t)
+	_, err = NewStr("1").NumberBigInt()
+	compare_bool_bool(testName, true, err != nil, t)
+}
+
+// go test -v -run Test_numberValueParsing_literal_is_json_L2
+func Test_numberValueParsing_literal_is_json_L2(t *testing.T) {
+	testName := "Test_numberValueParsing_literal_is_json_L2"
+	for _, valid := range []string{"0", "-0", "12", "-1.5", "0.25e-3", "1E+10", "1e5"} {
+		compare_bool_bool(testName+"_"+valid, true, numberValueParsing_literal_is_json_L2(valid), t)
+	}
+	for _, invalid := range []string{"", "-", "01", "+1", "1.", ".5", "1e", "1e+", "0x10", "1_000", "Infinity", "1.5.2"} {
+		compare_bool_bool(testName+"_"+invalid, false, numberValueParsing_literal_is_json_L2(invalid), t)
+	}
+}
+
+// go test -v -run Test_numberValueParsing_literal_is_integer_L2
+func Test_numberValueParsing_literal_is_integer_L2(t *testing.T) {
+	testName := "Test_numberValueParsing_literal_is_integer_L2"
+	compare_bool_bool(testName, true, numberValueParsing_literal_is_integer_L2("-123"), t)
+	compare_bool_bool(testName, false, numberValueParsing_literal_is_integer_L2("1.0"), t)
+	compare_bool_bool(testName, false, numberValueParsing_literal_is_integer_L2("-"), t)
+	compare_bool_bool(testName, false, numberValueParsing_literal_is_integer_L2(""), t)
+}
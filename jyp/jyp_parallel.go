@@ -0,0 +1,138 @@
+/*
+Copyright (c) 2024, Balazs Nyiro, balazs.nyiro.ca@gmail.com
+All rights reserved.
+
+This source code (all file in this repo) is licensed
+under the Apache-2 style license found in the
+LICENSE file in the root directory of this source tree.
+
+This module: parallel tokenization (stepA) of large sources.
+
+The src is split into chunks, and the chunks are tokenized in goroutines.
+The problem: a chunk can start inside a string, and that is known only
+when the previous chunks are processed. So every chunk is tokenized twice,
+speculatively: once as if it started outside of strings, once as if it
+started inside a string. When every chunk is ready, the right version of
+every chunk is selected in src order, and the token tables are stitched together.
+
+A chunk boundary is always after a whitespace or a structural char:
+  - outside of strings, a literal (number, true...) cannot be cut into two,
+  - inside a string, the first char of the chunk cannot be escaped.
+
+The result is identical to the sequential stepA.
+*/
+
+package jyp
+
+import "sync"
+
+// posStringStart state of a section that starts inside a string:
+// the string was opened in a previous chunk
+const stringStartedBeforeSection = -2
+
+// chunks smaller than this are not worth a goroutine
+const parallelChunkSizeMin = 256 * 1024
+
+type chunkTokenized struct {
+	tokens         tokenElems
+	posStringStart int // the state at the end of the chunk
+}
+
+func stepA__tokensTableDetect_parallel_L1(src []byte, numOfChunks int) tokenElems { // TESTED
+	boundaries := chunkBoundaries_detect(src, numOfChunks)
+	if len(boundaries) < 3 { // only one chunk
+		return stepA__tokensTableDetect_structuralTokens_strings_L1(src)
+	}
+
+	// [chunkId][0]: started outside of strings, [chunkId][1]: started in a string
+	chunks := make([][2]chunkTokenized, len(boundaries)-1)
+	wg := sync.WaitGroup{}
+	for chunkId := 0; chunkId < len(boundaries)-1; chunkId++ {
+		for version, posStringStart := range []int{-1, stringStartedBeforeSection} {
+			if chunkId == 0 && version == 1 {
+				continue // the src starts outside of strings
+			}
+			wg.Add(1)
+			go func(chunkId, version, posStringStart int) {
+				defer wg.Done()
+				posFrom, posTo := boundaries[chunkId], boundaries[chunkId+1]
+				tokens, posStringStartAtEnd := stepA__tokensTableDetect_section_L2(src, posFrom, posTo, posStringStart, make(tokenElems, 0, (posTo-posFrom)/8))
+				chunks[chunkId][version] = chunkTokenized{tokens: tokens, posStringStart: posStringStartAtEnd}
+			}(chunkId, version, posStringStart)
+		}
+	}
+	wg.Wait()
+	return chunks_stitch(src, chunks)
+}
+
+// the chunk start positions, and len(src) as the last elem
+func chunkBoundaries_detect(src []byte, numOfChunks int) []int { // TESTED
+	boundaries := []int{0}
+	if numOfChunks < 1 {
+		numOfChunks = 1
+	}
+	chunkSize := len(src)/numOfChunks + 1
+	for pos := chunkSize; pos < len(src); pos += chunkSize {
+		// the boundary is moved after the next whitespace or structural char
+		for pos < len(src) && !chunkBoundary_is_safe_after(src[pos-1]) {
+			pos++
+		}
+		if pos < len(src) && pos > boundaries[len(boundaries)-1] {
+			boundaries = append(boundaries, pos)
+		}
+	}
+	return append(boundaries, len(src))
+}
+
+func chunkBoundary_is_safe_after(oneByte byte) bool { // TESTED
+	return base__is_whitespace_byte(oneByte) || oneByte == '{' || oneByte == '}' || oneByte == '[' || oneByte == ']' || oneByte == ',' || oneByte == ':'
+}
+
+// select the right version of the chunks in src order, and join them
+func chunks_stitch(src []byte, chunks [][2]chunkTokenized) tokenElems { // TESTED
+	numOfTokens := 0
+	for _, chunk := range chunks {
+		numOfTokens += len(chunk[0].tokens)
+	}
+	tokenTable := make(tokenElems, 0, numOfTokens+1)
+
+	posStringStart := -1 // the state between the chunks
+	for _, chunkVersions := range chunks {
+		if posStringStart == -1 {
+			tokenTable = append(tokenTable, chunkVersions[0].tokens...)
+			posStringStart = chunkVersions[0].posStringStart
+			continue
+		}
+
+		chunk := chunkVersions[1]
+		tokensFirst := len(tokenTable)
+		tokenTable = append(tokenTable, chunk.tokens...)
+		// only the first token can be the string that was opened in a previous chunk
+		if len(tokenTable) > tokensFirst && tokenTable[tokensFirst].posInSrcFirst == stringStartedBeforeSection {
+			tokenTable[tokensFirst].posInSrcFirst = posStringStart
+		}
+		if chunk.posStringStart != stringStartedBeforeSection { // else: the whole chunk was in the string
+			posStringStart = chunk.posStringStart
+		}
+	}
+
+	if posStringStart != -1 {
+		tokenTable = append(tokenTable, tokenElem{tokenType: 'U', posInSrcFirst: posStringStart, posInSrcLast: len(src) - 1})
+	}
+	return tokenTable
+}
+
+// stepA, with or without goroutines, or the relaxed stepA
+func (opts Options) tokensTableDetect(src []byte) tokenElems {
+	if opts.Relaxed {
+		return tokensTable_comments_remove(stepA__tokensTableDetect_relaxed_L1(src))
+	}
+	numOfChunks := opts.Parallel
+	if numOfChunks > len(src)/parallelChunkSizeMin {
+		numOfChunks = len(src) / parallelChunkSizeMin
+	}
+	if numOfChunks < 2 {
+		return stepA__tokensTableDetect_structuralTokens_strings_L1(src)
+	}
+	return stepA__tokensTableDetect_parallel_L1(src, numOfChunks)
+}
@@ -0,0 +1,88 @@
+/*
+Copyright (c) 2024, Balazs Nyiro, balazs.nyiro.ca@gmail.com
+All rights reserved.
+
+This source code (all file in this repo) is licensed
+under the Apache-2 style license found in the
+LICENSE file in the root directory of this source tree.
+
+*/
+
+package jyp
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// go test -v -run Test_stepA_parallel
+func Test_stepA_parallel(t *testing.T) {
+	funName := "Test_stepA_parallel"
+
+	srcs := []string{
+		srcEverything,
+		`{"a b": "c, d", "e": ["f :g", "h\" i, j", "k\\", "l\\\" m\\\\", 12, true]}`,
+		`["long string with spaces, commas: [and] {brackets} in it", -12.5e+3, null, false]`,
+		`["escaped \\ , \" , \\\\" , "\"", " ", "x" ]`,
+		`{"unclosed": "string at the end, with , and spaces`,
+		`[1, 2, 3, 42`,
+		`  "árvíztűrő tükörfúrógép, 日本語 text"  `,
+		strings.Repeat(`{"id": 1234, "name": "a b c", "tags": ["x, y", "z\" w"]}, `, 50),
+	}
+
+	for srcId, srcStr := range srcs {
+		src := []byte(srcStr)
+		tokensWanted := stepA__tokensTableDetect_structuralTokens_strings_L1(src)
+		for numOfChunks := 1; numOfChunks < len(src) && numOfChunks < 300; numOfChunks++ {
+			testName := funName + "_src" + strconv.Itoa(srcId) + "_chunks" + strconv.Itoa(numOfChunks)
+			tokensReceived := stepA__tokensTableDetect_parallel_L1(src, numOfChunks)
+			compare_tokenElems(testName, tokensWanted, tokensReceived, t)
+		}
+	}
+}
+
+// go test -v -run Test_chunkBoundaries_detect
+func Test_chunkBoundaries_detect(t *testing.T) {
+	funName := "Test_chunkBoundaries_detect"
+
+	testName := funName + "_literals_not_cut"
+	src := []byte(`[123456, 7890, true]`)
+	boundaries := chunkBoundaries_detect(src, 4)
+	compare_int_int(testName, 0, boundaries[0], t)
+	compare_int_int(testName, len(src), boundaries[len(boundaries)-1], t)
+	for _, boundary := range boundaries[1 : len(boundaries)-1] {
+		compare_bool_bool(testName, true, chunkBoundary_is_safe_after(src[boundary-1]), t)
+	}
+
+	testName = funName + "_one_chunk"
+	boundaries = chunkBoundaries_detect([]byte(`12345678`), 3)
+	compare_int_int(testName, 2, len(boundaries), t)
+
+	testName = funName + "_empty"
+	boundaries = chunkBoundaries_detect([]byte{}, 3)
+	compare_int_int(testName, 2, len(boundaries), t)
+}
+
+// go test -v -run Test_JsonParseWithOptions
+func Test_JsonParseWithOptions(t *testing.T) {
+	funName := "Test_JsonParseWithOptions"
+	testName := funName + "_parallel"
+
+	srcStr := "[" + strings.Repeat(`{"id": 1234, "name": "a b c", "tags": ["x, y", "z\" w"]}, `, 20000) + "0]"
+	compare_tokenElems(testName, stepA__tokensTableDetect_structuralTokens_strings_L1([]byte(srcStr)), Options{Parallel: 8}.tokensTableDetect([]byte(srcStr)), t)
+
+	root, errorsCollected := JsonParseWithOptions(srcStr, Options{Parallel: 8})
+	compare_int_int(testName, 0, len(errorsCollected), t)
+	compare_int_int(testName, 20001, len(root.ValArray), t)
+	compare_str_str(testName, `z" w`, root.ValArray[19999].ValObject["tags"].ValArray[1].ValRunes, t)
+}
+
+func compare_tokenElems(testName string, tokensWanted, tokensReceived tokenElems, t *testing.T) {
+	compare_int_int(testName+"_len", len(tokensWanted), len(tokensReceived), t)
+	for pos := range tokensWanted {
+		compare_rune_rune(testName+"_type_"+strconv.Itoa(pos), tokensWanted[pos].tokenType, tokensReceived[pos].tokenType, t)
+		compare_int_int(testName+"_first_"+strconv.Itoa(pos), tokensWanted[pos].posInSrcFirst, tokensReceived[pos].posInSrcFirst, t)
+		compare_int_int(testName+"_last_"+strconv.Itoa(pos), tokensWanted[pos].posInSrcLast, tokensReceived[pos].posInSrcLast, t)
+	}
+}
@@ -0,0 +1,128 @@
+/*
+Copyright (c) 2024, Balazs Nyiro, balazs.nyiro.ca@gmail.com
+All rights reserved.
+
+This source code (all file in this repo) is licensed
+under the Apache-2 style license found in the
+LICENSE file in the root directory of this source tree.
+
+This module: source positions of the parsed values.
+
+	root, _ := jyp.JsonParseWithOptions(src, jyp.Options{Positions: true})
+	spans, err := root.PosGetPath("/spec/replicas")
+	fmt.Println("replicas is invalid, line", spans.Value.Start.Line, "column", spans.Value.Start.Column)
+
+With Options.Positions every value has a Pos: the span of the value in the src, and if the value
+is in an object, the span of its key. The same path format is used as in GetPath, and
+an array elem can be selected with its index: "/spec/containers/0/image".
+
+The positions are counted in one pass with the structure building, the src is not re-read for every value.
+Without Positions, Pos is nil: there is no extra cost. The recovered tree of Options.Recover has positions, too.
+*/
+
+package jyp
+
+import (
+	"errors"
+	"strconv"
+)
+
+// the same fields as in ParseError
+type SrcPosition struct {
+	Offset     int // rune position in the src, 0 based
+	ByteOffset int // byte position in the src, 0 based
+	Line       int // 1 based
+	Column     int // 1 based, counted in runes
+}
+
+type SrcSpan struct {
+	Start SrcPosition // the first char
+	End   SrcPosition // after the last char
+}
+
+type SrcSpans struct {
+	Value SrcSpan
+	Key   SrcSpan // the quoted key, if the value is in an object. Zero SrcSpan otherwise
+}
+
+// the positions are counted forward, from the last wanted position.
+// An earlier position in the same line is counted back, if an earlier line is wanted,
+// the counting is restarted from the beginning of the src
+type srcPositionCounter struct {
+	src []byte
+	now SrcPosition
+}
+
+func newSrcPositionCounter(src []byte) *srcPositionCounter {
+	return &srcPositionCounter{src: src, now: SrcPosition{Line: 1, Column: 1}}
+}
+
+func (counter *srcPositionCounter) position(posByte int) SrcPosition { // TESTED
+	for counter.now.ByteOffset > posByte { // back in the line: an error inside a token, after an error at the token start
+		b := counter.src[counter.now.ByteOffset-1]
+		if b == '\n' {
+			counter.now = SrcPosition{Line: 1, Column: 1}
+			break
+		}
+		counter.now.ByteOffset--
+		if b&0xC0 != 0x80 {
+			counter.now.Offset--
+			counter.now.Column--
+		}
+	}
+	for pos := counter.now.ByteOffset; pos < posByte && pos < len(counter.src); pos++ {
+		b := counter.src[pos]
+		counter.now.ByteOffset++
+		if b&0xC0 == 0x80 {
+			continue // utf8 continuation byte, it is not a new rune
+		}
+		counter.now.Offset++
+		if b == '\n' {
+			counter.now.Line++
+			counter.now.Column = 1
+		} else {
+			counter.now.Column++
+		}
+	}
+	return counter.now
+}
+
+// the span of one token
+func (counter *srcPositionCounter) span(token tokenElem) SrcSpan { // TESTED
+	return SrcSpan{Start: counter.position(token.posInSrcFirst), End: counter.position(token.posInSrcLast + 1)}
+}
+
+////////////////////////////////////////////////////////////////////////////////////
+
+// the positions of the value in the path, see the module description
+func (v JSON_value) PosGetPath(keysMerged string) (SrcSpans, error) { // TESTED
+	if len(keysMerged) < 2 {
+		return SrcSpans{}, errors.New(errorPrefix + "missing separator and key(s) in merged PosGetPath")
+	}
+	keys, _ := ObjPath_merged_expand__split_with_first_char(keysMerged)
+	return v.PosGetPathKeys(keys)
+}
+
+// an empty keys list means the value itself
+func (v JSON_value) PosGetPathKeys(keysEmbedded []string) (SrcSpans, error) { // TESTED
+	valueNow := v
+	for _, key := range keysEmbedded {
+		if valueNow.ValType == '[' {
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(valueNow.ValArray) {
+				return SrcSpans{}, errors.New(errorPrefix + "index (" + key + ") is not in array")
+			}
+			valueNow = valueNow.ValArray[index]
+			continue
+		}
+		child, isKnown := valueNow.ValObject[key]
+		if !isKnown {
+			return SrcSpans{}, errors.New(errorPrefix + "unknown object key (key:" + key + ")")
+		}
+		valueNow = child
+	}
+	if valueNow.Pos == nil {
+		return SrcSpans{}, errors.New(errorPrefix + "the positions are not saved, use Options.Positions in the parsing")
+	}
+	return *valueNow.Pos, nil
+}
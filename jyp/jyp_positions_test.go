@@ -0,0 +1,136 @@
+/*
+Copyright (c) 2024, Balazs Nyiro, balazs.nyiro.ca@gmail.com
+All rights reserved.
+
+This source code (all file in this repo) is licensed
+under the Apache-2 style license found in the
+LICENSE file in the root directory of this source tree.
+
+*/
+
+package jyp
+
+import "testing"
+
+// go test -v -run Test_JsonParse_Positions
+func Test_JsonParse_Positions(t *testing.T) {
+	funName := "Test_JsonParse_Positions"
+	src := `{
+  "kind": "Deployment",
+  "spec": {
+    "replicas": -3,
+    "név": "ő",
+    "containers": [{"image": "nginx"}, [1, 2]]
+  }
+}`
+	root, errorsCollected := JsonParseWithOptions(src, Options{Positions: true})
+	testName := funName + "_parse"
+	compare_int_int(testName, 0, len(errorsCollected), t)
+
+	testName = funName + "_scalar"
+	spans, err := root.PosGetPath("/spec/replicas")
+	compare_bool_bool(testName, true, err == nil, t)
+	compare_int_int(testName, 4, spans.Value.Start.Line, t)
+	compare_int_int(testName, 17, spans.Value.Start.Column, t)
+	compare_int_int(testName, 19, spans.Value.End.Column, t)
+	compare_str_str(testName, "-3", src[spans.Value.Start.ByteOffset:spans.Value.End.ByteOffset], t)
+	compare_int_int(testName, 4, spans.Key.Start.Line, t)
+	compare_int_int(testName, 5, spans.Key.Start.Column, t)
+	compare_str_str(testName, `"replicas"`, src[spans.Key.Start.ByteOffset:spans.Key.End.ByteOffset], t)
+
+	testName = funName + "_multibyte" // Offset and Column are counted in runes
+	spans, _ = root.PosGetPath("/spec/név")
+	compare_int_int(testName, 12, spans.Value.Start.Column, t)
+	compare_int_int(testName, spans.Value.Start.ByteOffset-1, spans.Value.Start.Offset, t)
+	compare_str_str(testName, `"ő"`, src[spans.Value.Start.ByteOffset:spans.Value.End.ByteOffset], t)
+
+	testName = funName + "_containers"
+	spans, _ = root.PosGetPathKeys([]string{"spec", "containers", "0", "image"})
+	compare_int_int(testName, 6, spans.Value.Start.Line, t)
+	compare_int_int(testName, 30, spans.Value.Start.Column, t)
+	spans, _ = root.PosGetPath("/spec/containers/1")
+	compare_str_str(testName, "[1, 2]", src[spans.Value.Start.ByteOffset:spans.Value.End.ByteOffset], t)
+	compare_int_int(testName, 0, spans.Key.Start.Line, t) // an array elem has no key
+	spans, _ = root.PosGetPath("/spec")
+	compare_int_int(testName, 3, spans.Value.Start.Line, t)
+	compare_int_int(testName, 7, spans.Value.End.Line, t)
+	compare_int_int(testName, 4, spans.Value.End.Column, t)
+	spans, _ = root.PosGetPathKeys([]string{})
+	compare_int_int(testName, len(src), spans.Value.End.ByteOffset, t)
+
+	testName = funName + "_errors"
+	_, err = root.PosGetPath("/spec/containers/2")
+	compare_bool_bool(testName, true, err != nil, t)
+	_, err = root.PosGetPath("/spec/unknown")
+	compare_bool_bool(testName, true, err != nil, t)
+	root, _ = JsonParse(src)
+	_, err = root.PosGetPath("/spec/replicas")
+	compare_bool_bool(testName, true, err != nil, t)
+
+	testName = funName + "_duplicates_collect"
+	root, _ = JsonParseWithOptions(`{"a": 1, "a": 2}`, Options{Positions: true, DuplicateKeys: DuplicateKeysCollect})
+	spans, err = root.PosGetPath("/a")
+	compare_bool_bool(testName, true, err == nil, t)
+	compare_int_int(testName, 2, spans.Key.Start.Column, t)
+	spans, _ = root.PosGetPath("/a/1")
+	compare_int_int(testName, 15, spans.Value.Start.Column, t)
+}
+
+// go test -v -run Test_JsonParse_Positions_Recover
+func Test_JsonParse_Positions_Recover(t *testing.T) {
+	funName := "Test_JsonParse_Positions_Recover" // the broken documents have positions, too
+	src := `{
+  "spec": {"replicas": tru, "image": },
+  "ports": [80 443,
+}`
+	root, errorsCollected := JsonParseWithOptions(src, Options{Recover: true, Positions: true})
+	compare_bool_bool(funName, true, len(errorsCollected) > 0, t)
+
+	testName := funName + "_broken_literal"
+	spans, err := root.PosGetPath("/spec/replicas")
+	compare_bool_bool(testName, true, err == nil, t)
+	compare_str_str(testName, "tru", src[spans.Value.Start.ByteOffset:spans.Value.End.ByteOffset], t)
+	compare_int_int(testName, 2, spans.Value.Start.Line, t)
+	compare_int_int(testName, 24, spans.Value.Start.Column, t)
+	compare_str_str(testName, `"replicas"`, src[spans.Key.Start.ByteOffset:spans.Key.End.ByteOffset], t)
+
+	testName = funName + "_missing_value" // an empty span, where the value is missing
+	spans, _ = root.PosGetPath("/spec/image")
+	compare_int_int(testName, 38, spans.Value.Start.Column, t)
+	compare_int_int(testName, spans.Value.Start.ByteOffset, spans.Value.End.ByteOffset, t)
+	compare_str_str(testName, `"image"`, src[spans.Key.Start.ByteOffset:spans.Key.End.ByteOffset], t)
+
+	testName = funName + "_unclosed_container" // closed at the closer of its parent
+	spans, _ = root.PosGetPath("/ports/0")
+	compare_str_str(testName, "80", src[spans.Value.Start.ByteOffset:spans.Value.End.ByteOffset], t)
+	spans, _ = root.PosGetPath("/ports")
+	compare_str_str(testName, "[80 443,\n", src[spans.Value.Start.ByteOffset:spans.Value.End.ByteOffset], t)
+	spans, _ = root.PosGetPathKeys([]string{})
+	compare_int_int(testName, len(src), spans.Value.End.ByteOffset, t)
+}
+
+// go test -v -run Test_srcPositionCounter
+func Test_srcPositionCounter(t *testing.T) {
+	testName := "Test_srcPositionCounter"
+	src := []byte("ab\nő\tc\nd")
+	counter := newSrcPositionCounter(src)
+	position := counter.position(6) // c
+	compare_int_int(testName, 2, position.Line, t)
+	compare_int_int(testName, 3, position.Column, t)
+	compare_int_int(testName, 5, position.Offset, t)
+	position = counter.position(5) // backward in the line: counted back
+	compare_int_int(testName, 2, position.Line, t)
+	compare_int_int(testName, 2, position.Column, t)
+	compare_int_int(testName, 4, position.Offset, t)
+	position = counter.position(2) // backward to an earlier line: restarted
+	compare_int_int(testName, 1, position.Line, t)
+	compare_int_int(testName, 3, position.Column, t)
+	position = counter.position(100) // after the src: the end of the src
+	compare_int_int(testName, 3, position.Line, t)
+	compare_int_int(testName, 2, position.Column, t)
+	compare_int_int(testName, len(src), position.ByteOffset, t)
+
+	span := newSrcPositionCounter(src).span(tokenElem{tokenType: '0', posInSrcFirst: 3, posInSrcLast: 4})
+	compare_int_int(testName, 1, span.Start.Column, t)
+	compare_int_int(testName, 2, span.End.Column, t)
+}
@@ -0,0 +1,330 @@
+/*
+Copyright (c) 2024, Balazs Nyiro, balazs.nyiro.ca@gmail.com
+All rights reserved.
+
+This source code (all file in this repo) is licensed
+under the Apache-2 style license found in the
+LICENSE file in the root directory of this source tree.
+
+This module: error recovering parse, for editors and linters.
+
+	root, errorsCollected := jyp.JsonParseWithOptions(src, jyp.Options{Recover: true})
+
+Without Recover, an invalid src gives an empty root value. With Recover, every
+problem is reported (as before), and the best-effort tree is built, too:
+
+  - after a problem, the building is resynchronized at the next comma or closer,
+  - a missing or invalid value is a broken node: ValType '?', the invalid literal is in ValRunes,
+  - the errors are attached to the node where they were detected, in Errors:
+    an invalid string/number/literal to its node, a missing colon, comma or value,
+    an unclosed container, or a problematic key to the container.
+    The errors that can't be attached to a node (after the root value, for example) are in the root.
+
+IsBroken() reports the marked nodes. Repr() writes a '?' node as null.
+With Options.Positions every node has a Pos, as in a valid tree: a missing value has an
+empty span where it is missing, an unclosed container ends where it is closed by the recovery.
+The comments are not saved in the recovered tree. If a limit of Options.Limits is exceeded,
+there is no recovery: the src is not processed further.
+*/
+
+package jyp
+
+import (
+	"errors"
+	"sort"
+)
+
+// a node with a problem: an invalid/missing value, or a node with attached errors
+func (v JSON_value) IsBroken() bool {
+	return v.ValType == '?' || len(v.Errors) > 0
+}
+
+// a container of the recovering building, that is not closed yet
+type containerRecovering struct {
+	elem       JSON_value
+	objKey     string
+	posStart   SrcPosition // Options.Positions: the position of the opener
+	objKeySpan SrcSpan     // Options.Positions: the span of the key of the actual child
+
+	/* the state of the container:
+	   k  key wanted      - after { and comma, objects only
+	   :  colon wanted    - after a key
+	   v  value wanted    - after a colon, after [ and comma in arrays
+	   ,  comma or closer - after a value                                */
+	state rune
+
+	objKeysCollected map[string]bool // DuplicateKeysCollect: the keys where the values are collected already
+}
+
+// with a duplicated key, one of the values can be dropped: its errors are moved into the object
+func (container *containerRecovering) obj_child_add(value JSON_value, policy DuplicateKeyPolicy) {
+	if value.Pos != nil {
+		value.Pos.Key = container.objKeySpan
+	}
+	valueBefore, isUsed := container.elem.ValObject[container.objKey]
+	container.objKeysCollected = objValue_add_L2(container.elem, container.objKey, value, policy, container.objKeysCollected)
+	if isUsed && policy == DuplicateKeysFirstWins {
+		container.elem.Errors = append(container.elem.Errors, recover_errors_of_tree(value)...)
+	} else if isUsed && policy != DuplicateKeysCollect {
+		container.elem.Errors = append(container.elem.Errors, recover_errors_of_tree(valueBefore)...)
+	}
+}
+
+// the token table is not validated: the errors of stepB are passed, the tree is built anyway
+func stepC__JSON_structure_building_recover_L1(src []byte, tokensTable tokenElems, errorsCollected []error, opts Options) JSON_value { // TESTED
+	for _, err := range errorsCollected {
+		if errors.Is(err, ErrLimitExceeded) {
+			return JSON_value{}
+		}
+	}
+
+	errorsOfTokens, errorsAttached := recover_errors_of_tokens(tokensTable, errorsCollected)
+	errorsAttach := func(value *JSON_value, posToken int) {
+		for _, posError := range errorsOfTokens[posToken] {
+			value.Errors = append(value.Errors, errorsCollected[posError])
+			errorsAttached[posError] = true
+		}
+	}
+
+	root := JSON_value{ValType: '?'}
+	rootIsBuilt := false
+	containers := []containerRecovering{}
+
+	var positions *srcPositionCounter
+	if opts.Positions {
+		positions = newSrcPositionCounter(src)
+	}
+	// a missing key or value has an empty span at the place where it is missing
+	spanMissing := func(posByte int) SrcSpan {
+		if !opts.Positions {
+			return SrcSpan{}
+		}
+		posMissing := positions.position(posByte)
+		return SrcSpan{Start: posMissing, End: posMissing}
+	}
+	valueMissing := func(posByte int) JSON_value {
+		value := JSON_value{ValType: '?'}
+		if opts.Positions {
+			value.Pos = &SrcSpans{Value: spanMissing(posByte)}
+		}
+		return value
+	}
+
+	// a complete value: it is the root, or the child of the innermost container
+	valueAdd := func(value JSON_value) {
+		if len(containers) == 0 {
+			root = value
+			rootIsBuilt = true
+			return
+		}
+		parent := &containers[len(containers)-1]
+		if parent.elem.ValType == '{' {
+			parent.obj_child_add(value, opts.DuplicateKeys)
+		} else {
+			parent.elem.ValArray = append(parent.elem.ValArray, value)
+		}
+		parent.state = ','
+	}
+
+	// the innermost container is closed: posCloser is the byte position of its closer, posEnd is after it.
+	// if a key has no value, a missing value is added
+	containerClose := func(posCloser, posEnd int) {
+		container := containers[len(containers)-1]
+		if container.state == ':' || (container.state == 'v' && container.elem.ValType == '{') {
+			container.obj_child_add(valueMissing(posCloser), opts.DuplicateKeys)
+		}
+		if opts.Positions {
+			container.elem.Pos = &SrcSpans{Value: SrcSpan{Start: container.posStart, End: positions.position(posEnd)}}
+		}
+		containers = containers[:len(containers)-1]
+		valueAdd(container.elem)
+	}
+
+	for pos, token := range tokensTable {
+		tokenType := token.tokenType
+		if rootIsBuilt && len(containers) == 0 {
+			break // only one root value is built, the errors of the next tokens are attached to the root
+		}
+		var parent *containerRecovering // the pointer is used only before the next append/removal in containers
+		if len(containers) > 0 {
+			parent = &containers[len(containers)-1]
+		}
+
+		if tokenType == 'C' || tokenType == '/' { // unclosed comment, comment
+			continue
+		}
+
+		if tokenType == '}' || tokenType == ']' {
+			opener := '['
+			if tokenType == '}' {
+				opener = '{'
+			}
+			posOpener := len(containers) - 1
+			for posOpener >= 0 && containers[posOpener].elem.ValType != opener {
+				posOpener--
+			}
+			if posOpener < 0 { // unpaired closer, it is ignored
+				if parent != nil {
+					errorsAttach(&parent.elem, pos)
+				}
+				continue
+			}
+			for len(containers) > posOpener+1 { // the unclosed containers between them
+				containerClose(token.posInSrcFirst, token.posInSrcFirst)
+			}
+			errorsAttach(&containers[posOpener].elem, pos)
+			containerClose(token.posInSrcFirst, token.posInSrcLast+1)
+			continue
+		}
+
+		if parent == nil { // root level
+			if tokenType == ',' || tokenType == ':' {
+				continue
+			}
+		} else {
+			isObject := parent.elem.ValType == '{'
+			isKeyWanted := isObject && (parent.state == 'k' || parent.state == ',') // after a missing comma, too
+			if tokenType == ',' || tokenType == ':' || (isKeyWanted && tokenType != '{' && tokenType != '[') {
+				errorsAttach(&parent.elem, pos) // the errors of the separators and keys belong to the container
+			}
+			if isObject && parent.state == ',' && tokenType != '{' && tokenType != '[' && !recover_token_is_key(src, token, opts) {
+				continue // a second value after a value, without comma and key: it is dropped
+			}
+
+			if tokenType == ',' {
+				if parent.state == 'v' || (isObject && parent.state == ':') { // missing value
+					valueAdd(valueMissing(token.posInSrcFirst))
+				}
+				parent = &containers[len(containers)-1]
+				parent.state = 'v'
+				if isObject {
+					parent.state = 'k'
+				}
+				continue
+			}
+
+			if tokenType == ':' {
+				if isKeyWanted { // missing key
+					parent.objKey = ""
+					parent.objKeySpan = spanMissing(token.posInSrcFirst)
+				}
+				if isObject {
+					parent.state = 'v'
+				}
+				continue
+			}
+
+			if isKeyWanted {
+				if tokenType != '{' && tokenType != '[' {
+					parent.objKey = recover_key_text(src, token)
+					if opts.Positions {
+						parent.objKeySpan = positions.span(token)
+					}
+					parent.state = ':'
+					continue
+				}
+				parent.objKey = "" // a container as key: it is used as the value of a missing key
+				parent.objKeySpan = spanMissing(token.posInSrcFirst)
+			}
+		}
+
+		// value start
+		if tokenType == '{' || tokenType == '[' {
+			container := containerRecovering{elem: NewArr(), state: 'v'}
+			if tokenType == '{' {
+				container.elem = NewObj()
+				container.state = 'k'
+			}
+			if opts.Positions {
+				container.posStart = positions.position(token.posInSrcFirst)
+			}
+			errorsAttach(&container.elem, pos)
+			containers = append(containers, container)
+			continue
+		}
+		value := recover_scalar_building(src, token, opts)
+		if opts.Positions {
+			value.Pos = &SrcSpans{Value: positions.span(token)}
+		}
+		errorsAttach(&value, pos)
+		valueAdd(value)
+	}
+
+	for len(containers) > 0 { // unclosed containers at the end of the src
+		containerClose(len(src), len(src))
+	}
+
+	for posError, err := range errorsCollected {
+		if !errorsAttached[posError] {
+			root.Errors = append(root.Errors, err)
+		}
+	}
+	return root
+}
+
+// the indexes of the errors for every token: the error is in the token, or after it.
+// errorsAttached: the errors that are attached to a node already
+func recover_errors_of_tokens(tokensTable tokenElems, errorsCollected []error) (map[int][]int, []bool) { // TESTED
+	errorsOfTokens := map[int][]int{}
+	for posError, err := range errorsCollected {
+		var parseErr ParseError
+		if !errors.As(err, &parseErr) || len(tokensTable) == 0 {
+			continue
+		}
+		posToken := sort.Search(len(tokensTable), func(pos int) bool {
+			return tokensTable[pos].posInSrcFirst > parseErr.ByteOffset
+		}) - 1
+		if posToken >= 0 {
+			errorsOfTokens[posToken] = append(errorsOfTokens[posToken], posError)
+		}
+	}
+	return errorsOfTokens, make([]bool, len(errorsCollected))
+}
+
+// the key text of any token: a quoted key is interpreted, an unclosed one is read till the end
+func recover_key_text(src []byte, token tokenElem) string { // TESTED
+	if token.tokenType == 'U' {
+		return stringValueParsing_rawToInterpretedCharacters_L2(src[token.posInSrcFirst+1 : token.posInSrcLast+1])
+	}
+	return objKey_from_token_L2(src, token)
+}
+
+// every error in the value and in its children, without recursion
+func recover_errors_of_tree(value JSON_value) []error { // TESTED
+	errorsCollected := []error{}
+	values := []JSON_value{value}
+	for len(values) > 0 {
+		valueNow := values[len(values)-1]
+		values = values[:len(values)-1]
+		errorsCollected = append(errorsCollected, valueNow.Errors...)
+		values = append(values, valueNow.ValArray...)
+		for _, key := range valueNow.ValObject_keys_ordered() {
+			values = append(values, valueNow.ValObject[key])
+		}
+	}
+	return errorsCollected
+}
+
+// after a value in an object, a string is the next key with a missing comma
+func recover_token_is_key(src []byte, token tokenElem, opts Options) bool { // TESTED
+	if opts.Relaxed {
+		return relaxed_key_is_valid(src, token) || token.tokenType == 'U'
+	}
+	return token.tokenType == '"' || token.tokenType == 'U'
+}
+
+// an invalid literal is a broken '?' node, the text of the literal is in ValRunes
+func recover_scalar_building(src []byte, token tokenElem, opts Options) JSON_value { // TESTED
+	textInSrc := base__read_sourceCode_section_basedOnTokenPositions(src, token, false)
+	if token.tokenType == 'U' { // unclosed string: the rest of the src is in it
+		return NewStr(stringValueParsing_rawToInterpretedCharacters_L2(textInSrc[1:]))
+	}
+	isValid := token.tokenType != '?' && token.tokenType != 'i'
+	if token.tokenType == '0' { // out of the float64 range: 1e400 is valid only with NumbersRaw
+		isValid = numberValueParsing_literal_is_valid_L2(string(textInSrc), opts)
+	}
+	if !isValid {
+		return JSON_value{ValType: '?', ValRunes: string(textInSrc)}
+	}
+	return stepC__scalar_building_L2(src, token, opts)
+}
@@ -0,0 +1,137 @@
+/*
+Copyright (c) 2024, Balazs Nyiro, balazs.nyiro.ca@gmail.com
+All rights reserved.
+
+This source code (all file in this repo) is licensed
+under the Apache-2 style license found in the
+LICENSE file in the root directory of this source tree.
+
+*/
+
+package jyp
+
+import (
+	"errors"
+	"testing"
+)
+
+// go test -v -run Test_JsonParse_Recover
+func Test_JsonParse_Recover(t *testing.T) {
+	funName := "Test_JsonParse_Recover"
+
+	testName := funName + "_config"
+	src := `{
+  "name": "web",
+  "port": 80 80,
+  "debug": tru,
+  "paths": ["/a", "/b",, "/c"],
+  "limits": {"cpu": 2 "mem": },
+  "tags": ["x"
+}`
+	root, errorsCollected := JsonParseWithOptions(src, Options{Recover: true})
+	compare_int_int(testName, 6, len(errorsCollected), t)
+	compare_rune_rune(testName, '{', root.ValType, t)
+	compare_str_str(testName, `{"debug":null,"limits":{"cpu":2,"mem":null},"name":"web","paths":["/a","/b",null,"/c"],"port":80,"tags":["x"]}`, root.Repr(), t)
+
+	compare_bool_bool(testName, false, root.ValObject["name"].IsBroken(), t)
+	compare_bool_bool(testName, true, root.ValObject["debug"].IsBroken(), t)
+	compare_str_str(testName, "tru", root.ValObject["debug"].ValRunes, t)
+	compare_bool_bool(testName, true, errors.Is(root.ValObject["debug"].Errors[0], ErrInvalidLiteral), t)
+	compare_bool_bool(testName, true, root.ValObject["paths"].ValArray[2].IsBroken(), t)
+	compare_bool_bool(testName, true, errors.Is(root.ValObject["paths"].Errors[0], ErrMissingValue), t)
+	compare_bool_bool(testName, true, errors.Is(root.ValObject["limits"].Errors[0], ErrMissingComma), t)
+	compare_bool_bool(testName, true, root.ValObject["limits"].ValObject["mem"].IsBroken(), t)
+	compare_int_int(testName, 2, len(root.Errors), t)
+	compare_bool_bool(testName, true, errors.Is(root.Errors[0], ErrMissingComma), t)   // "port": 80 80, the second value is dropped
+	compare_bool_bool(testName, true, errors.Is(root.Errors[1], ErrUnpairedCloser), t) // the closer of the root, tags is unclosed
+	compare_int_int(testName, 8, root.Errors[1].(ParseError).Line, t)
+
+	testName = funName + "_valid_src" // the same tree as without Recover
+	root, errorsCollected = JsonParseWithOptions(`{"a": [1, {"b": null}]}`, Options{Recover: true})
+	compare_int_int(testName, 0, len(errorsCollected), t)
+	compare_str_str(testName, `{"a":[1,{"b":null}]}`, root.Repr(), t)
+
+	testName = funName + "_without_recover"
+	root, _ = JsonParse(`[1, tru]`)
+	compare_rune_rune(testName, 0, root.ValType, t)
+}
+
+// go test -v -run Test_stepC__JSON_structure_building_recover_L1
+func Test_stepC__JSON_structure_building_recover_L1(t *testing.T) {
+	funName := "Test_stepC__JSON_structure_building_recover_L1"
+
+	testName := funName + "_trees"
+	srcTrees := map[string]string{
+		`[1, 2`:              `[1,2]`,
+		`[1 2]`:              `[1,2]`,
+		`[,1]`:               `[null,1]`,
+		`[1,]`:               `[1]`,
+		`[1, [2, 3}, 4]`:     `[1,[2,3,4]]`, // the unpaired } is ignored
+		`[1, {"a": 2]]`:      `[1,{"a":2}]`,
+		`[1]]] 2`:            `[1]`,
+		`{"a" 1, "b": 2}`:    `{"a":1,"b":2}`,
+		`{"a":, "b": 2}`:     `{"a":null,"b":2}`,
+		`{"a", "b": 2}`:      `{"a":null,"b":2}`,
+		`{1: 2}`:             `{"1":2}`,
+		`{: 2}`:              `{"":2}`,
+		`{"a": "unclosed}`:   `{"a":"unclosed}"}`,
+		`{"a": 012, "b": 1}`: `{"a":null,"b":1}`,
+		`{"a": 1 "b": 2}`:    `{"a":1,"b":2}`,
+		`"abc`:               `"abc"`,
+		`,`:                  `null`,
+		``:                   `null`,
+	}
+	for src, reprWanted := range srcTrees {
+		root, errorsCollected := JsonParseWithOptions(src, Options{Recover: true})
+		compare_bool_bool(testName+" "+src, true, len(errorsCollected) > 0, t)
+		compare_str_str(testName+" "+src, reprWanted, root.Repr(), t)
+	}
+
+	testName = funName + "_every_error_is_attached"
+	src := `[1, {"a" 2, "b": [tru, 3 4}, "x\q"]] 5`
+	root, errorsCollected := JsonParseWithOptions(src, Options{Recover: true})
+	compare_int_int(testName, len(errorsCollected), len(recover_errors_of_tree(root)), t)
+
+	testName = funName + "_dropped_duplicate" // the errors of the dropped value are in the object
+	root, errorsCollected = JsonParseWithOptions(`{"a": [tru], "a": 1}`, Options{Recover: true})
+	compare_str_str(testName, `{"a":1}`, root.Repr(), t)
+	compare_int_int(testName, 1, len(root.Errors), t)
+	compare_bool_bool(testName, true, errors.Is(root.Errors[0], ErrInvalidLiteral), t)
+	root, errorsCollected = JsonParseWithOptions(`{"a": 1, "a": [tru]}`, Options{Recover: true, DuplicateKeys: DuplicateKeysFirstWins})
+	compare_str_str(testName, `{"a":1}`, root.Repr(), t)
+	compare_int_int(testName, 1, len(root.Errors), t)
+
+	testName = funName + "_limits"
+	root, errorsCollected = JsonParseWithOptions(`[[[1, tru]]]`, Options{Recover: true, Limits: Limits{MaxDepth: 2}})
+	compare_int_int(testName, 1, len(errorsCollected), t)
+	compare_rune_rune(testName, 0, root.ValType, t)
+}
+
+// go test -v -run Test_recover_errors_of_tokens
+func Test_recover_errors_of_tokens(t *testing.T) {
+	testName := "Test_recover_errors_of_tokens"
+	src := []byte(`[1, tru 2]`)
+	tokensTable := stepA__tokensTableDetect_structuralTokens_strings_L1(src)
+	errorsCollected := []error{newParseError(src, 4, '?', ErrInvalidLiteral, ""), newParseError(src, 8, '0', ErrMissingComma, ""), errors.New("no position")}
+	errorsOfTokens, errorsAttached := recover_errors_of_tokens(tokensTable, errorsCollected)
+	compare_int_int(testName, 2, len(errorsOfTokens), t)
+	compare_int_int(testName, 0, errorsOfTokens[3][0], t) // tru
+	compare_int_int(testName, 1, errorsOfTokens[4][0], t) // 2
+	compare_int_int(testName, 3, len(errorsAttached), t)
+}
+
+// go test -v -run Test_recover_scalar_building
+func Test_recover_scalar_building(t *testing.T) {
+	testName := "Test_recover_scalar_building"
+	src := []byte(`[012, 0x1F, nul, "ab`)
+	tokensTable := stepA__tokensTableDetect_relaxed_L1(src)
+	compare_rune_rune(testName, '?', recover_scalar_building(src, tokensTable[1], Options{}).ValType, t)
+	compare_str_str(testName, "012", recover_scalar_building(src, tokensTable[1], Options{}).ValRunes, t)
+	compare_int_int(testName, 31, recover_scalar_building(src, tokensTable[3], Options{Relaxed: true}).ValNumberInt, t)
+	compare_rune_rune(testName, '?', recover_scalar_building(src, tokensTable[5], Options{Relaxed: true}).ValType, t)
+	compare_str_str(testName, "ab", recover_scalar_building(src, tokensTable[7], Options{}).ValRunes, t)
+	compare_str_str(testName, "ab", recover_key_text(src, tokensTable[7]), t)
+	compare_bool_bool(testName, true, recover_token_is_key(src, tokensTable[7], Options{}), t)
+	compare_bool_bool(testName, false, recover_token_is_key(src, tokensTable[5], Options{}), t)
+	compare_bool_bool(testName, true, recover_token_is_key(src, tokensTable[5], Options{Relaxed: true}), t) // nul is an identifier
+}
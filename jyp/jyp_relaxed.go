@@ -0,0 +1,238 @@
+/*
+Copyright (c) 2024, Balazs Nyiro, balazs.nyiro.ca@gmail.com
+All rights reserved.
+
+This source code (all file in this repo) is licensed
+under the Apache-2 style license found in the
+LICENSE file in the root directory of this source tree.
+
+This module: relaxed (JSON5) parsing, https://json5.org
+
+	root, errs := jyp.JsonParseWithOptions(src, jyp.Options{Relaxed: true})
+
+Accepted in relaxed mode, over the standard json:
+  - // line comments, and /* block comments (closed with star + slash),
+  - trailing commas in objects and arrays,
+  - 'single quoted' strings (with \' escaping),
+  - the string escapes \v, \0 (not followed by a digit), \xHH,
+  - unquoted identifier keys: {name: "x", $id: 1, _private: 2},
+  - hexadecimal numbers: 0x1F, -0xff,
+  - leading + sign, leading or trailing decimal point: +1, .5, 5.
+  - Infinity, -Infinity, NaN.
+    They have no JSON form: Repr() writes them as null, as JavaScript JSON.stringify.
+
+Not accepted from JSON5: the line continuation (a backslash before a line break) in strings,
+the \ escape of the other chars (\a is not a), and the whitespaces out of JSON:
+\v, \f, NBSP and the Unicode line/paragraph separators.
+
+The relaxed tokenizer is a separated stepA (the standard one is not slowed
+down with comment and single quote detection). It has more token types:
+  /  comment            removed from the table before stepB
+  C  unclosed comment   the src ended inside a block comment
+  i  identifier         accepted only as object key
+stepB and stepC get the Relaxed option, too: trailing commas and identifier
+keys are accepted there, the special numbers are converted in stepC.
+*/
+
+package jyp
+
+import (
+	"bytes"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+func stepA__tokensTableDetect_relaxed_L1(src []byte) tokenElems { // TESTED
+	tokenTable := make(tokenElems, 0, len(src)/8)
+	tokenAdd := func(typeOfToken rune, posFirst, posLast int) {
+		if typeOfToken == '?' {
+			typeOfToken = relaxed_literal_type(src[posFirst : posLast+1])
+		}
+		tokenTable = append(tokenTable, tokenElem{tokenType: typeOfToken, posInSrcFirst: posFirst, posInSrcLast: posLast})
+	}
+
+	posUnknownBlockStart := -1
+	unknownBlockClose := func(posLast int) {
+		if posUnknownBlockStart != -1 {
+			tokenAdd('?', posUnknownBlockStart, posLast)
+			posUnknownBlockStart = -1
+		}
+	}
+
+	for pos := 0; pos < len(src); pos++ {
+		byteNow := src[pos]
+		byteNext := base__srcGetChar__safeOverindexing(src, pos+1)
+
+		if base__is_whitespace_byte(byteNow) {
+			unknownBlockClose(pos - 1)
+
+		} else if byteNow == '{' || byteNow == '}' || byteNow == '[' || byteNow == ']' || byteNow == ',' || byteNow == ':' {
+			unknownBlockClose(pos - 1)
+			tokenAdd(rune(byteNow), pos, pos)
+
+		} else if byteNow == '"' || byteNow == '\'' { // a string can be closed only with its opener quote
+			unknownBlockClose(pos - 1)
+			posClose := relaxed_string_close_find(src, pos)
+			if posClose == -1 {
+				tokenAdd('U', pos, len(src)-1)
+				pos = len(src)
+			} else {
+				tokenAdd('"', pos, posClose)
+				pos = posClose
+			}
+
+		} else if byteNow == '/' && byteNext == '/' { // line comment, till the end of the line
+			unknownBlockClose(pos - 1)
+			posLast := len(src) - 1
+			if posNewline := bytes.IndexByte(src[pos:], '\n'); posNewline != -1 {
+				posLast = pos + posNewline - 1
+			}
+			tokenAdd('/', pos, posLast)
+			pos = posLast
+
+		} else if byteNow == '/' && byteNext == '*' { // block comment
+			unknownBlockClose(pos - 1)
+			posEnd := bytes.Index(src[pos+2:], []byte("*/"))
+			if posEnd == -1 {
+				tokenAdd('C', pos, len(src)-1)
+				pos = len(src)
+			} else {
+				posLast := pos + 2 + posEnd + 1
+				tokenAdd('/', pos, posLast)
+				pos = posLast
+			}
+
+		} else if posUnknownBlockStart == -1 {
+			posUnknownBlockStart = pos
+		}
+	}
+	unknownBlockClose(len(src) - 1)
+	return tokenTable
+}
+
+// the position of the closing quote, or -1 if the string is not closed
+func relaxed_string_close_find(src []byte, posOpener int) int { // TESTED
+	quote := src[posOpener]
+	for pos := posOpener + 1; pos < len(src); pos++ {
+		if src[pos] == '\\' {
+			pos++ // the escaped char is skipped
+		} else if src[pos] == quote {
+			return pos
+		}
+	}
+	return -1
+}
+
+// true/false/null, number, identifier or invalid literal
+func relaxed_literal_type(textInSrc []byte) rune { // TESTED
+	text := string(textInSrc)
+	if text == "true" {
+		return 't'
+	}
+	if text == "false" {
+		return 'f'
+	}
+	if text == "null" {
+		return 'n'
+	}
+	if text == "Infinity" || text == "NaN" || strings.ContainsRune("+-.0123456789", rune(text[0])) {
+		return '0'
+	}
+	if relaxed_is_identifier_name(text) {
+		return 'i'
+	}
+	return '?'
+}
+
+// ECMAScript IdentifierName, without \u escapes: letters, digits, $ and _, not started with a digit
+func relaxed_is_identifier_name(text string) bool { // TESTED
+	if text == "" {
+		return false
+	}
+	for pos, oneRune := range text {
+		isValid := oneRune == '$' || oneRune == '_' || unicode.IsLetter(oneRune) || (pos > 0 && unicode.IsDigit(oneRune))
+		if !isValid || oneRune == utf8.RuneError {
+			return false
+		}
+	}
+	return true
+}
+
+// in relaxed mode, an object key can be a string or an identifier (true, Infinity... are identifiers too)
+func relaxed_key_is_valid(src []byte, token tokenElem) bool { // TESTED
+	if token.tokenType == '"' {
+		return true
+	}
+	return relaxed_is_identifier_name(string(base__read_sourceCode_section_basedOnTokenPositions(src, token, false)))
+}
+
+func tokensTable_comments_remove(tokens tokenElems) tokenElems { // TESTED
+	tokensWithoutComments := make(tokenElems, 0, len(tokens))
+	for _, token := range tokens {
+		if token.tokenType != '/' {
+			tokensWithoutComments = append(tokensWithoutComments, token)
+		}
+	}
+	return tokensWithoutComments
+}
+
+// the JSON5 numbers: hexa, Infinity, NaN, leading + and decimal points
+func numberValueParsing_textToNumber_relaxed_L2(textInSrc string) (JSON_value, bool) { // TESTED
+	sign, body := "", textInSrc
+	if strings.HasPrefix(body, "+") || strings.HasPrefix(body, "-") {
+		sign, body = body[:1], body[1:]
+	}
+
+	if body == "Infinity" {
+		if sign == "-" {
+			return NewNumFloat(math.Inf(-1)), true
+		}
+		return NewNumFloat(math.Inf(1)), true
+	}
+	if body == "NaN" {
+		return NewNumFloat(math.NaN()), true
+	}
+
+	if strings.HasPrefix(body, "0x") || strings.HasPrefix(body, "0X") {
+		num, err := strconv.ParseInt(sign+body[2:], 16, 64)
+		if err != nil {
+			return JSON_value{}, false
+		}
+		return NewNumInt(int(num)), true
+	}
+
+	// JSON5 decimals: .5 and 5. are accepted, they are completed to a JSON number.
+	// the Go specific number formats are not accepted (inf, 1_000, 0x1p-2), neither the leading zeros
+	mantissa := body
+	if posExponent := strings.IndexAny(body, "eE"); posExponent != -1 {
+		mantissa = body[:posExponent]
+	}
+	if !strings.ContainsAny(mantissa, "0123456789") { // . or .e5
+		return JSON_value{}, false
+	}
+	bodyJson := body
+	if strings.HasPrefix(bodyJson, ".") {
+		bodyJson = "0" + bodyJson
+	}
+	bodyJson = strings.Replace(strings.Replace(bodyJson, ".e", ".0e", 1), ".E", ".0E", 1)
+	if strings.HasSuffix(bodyJson, ".") {
+		bodyJson += "0"
+	}
+	if strings.HasPrefix(bodyJson, "-") || !numberValueParsing_literal_is_json_L2(bodyJson) {
+		return JSON_value{}, false
+	}
+	return numberValueParsing_textToNumber_L2(sign + bodyJson)
+}
+
+// the 2 hexa digits of the \xHH escape
+func relaxed_hexa2_to_intVal(src []byte, pos int) (int, bool) { // TESTED
+	digitHigh, errHigh := base__hexaRune_to_intVal(rune(base__srcGetChar__safeOverindexing(src, pos)))
+	digitLow, errLow := base__hexaRune_to_intVal(rune(base__srcGetChar__safeOverindexing(src, pos+1)))
+	if errHigh != nil || errLow != nil {
+		return 0, false
+	}
+	return digitHigh*16 + digitLow, true
+}
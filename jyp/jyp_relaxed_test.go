@@ -0,0 +1,190 @@
+/*
+Copyright (c) 2024, Balazs Nyiro, balazs.nyiro.ca@gmail.com
+All rights reserved.
+
+This source code (all file in this repo) is licensed
+under the Apache-2 style license found in the
+LICENSE file in the root directory of this source tree.
+
+*/
+
+package jyp
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+var srcJson5 = `// config of the service
+{
+  name: 'web \'frontend\'',   /* single quoted, escaped quote */
+  $id: 0x1F,
+  _private: -0xff,
+  "quoted": "double",
+  ratio: .5,
+  half: 5.,
+  plus: +1,
+  inf: Infinity,
+  negInf: -Infinity,
+  notNum: NaN,
+  true: "reserved word as key",
+  list: [1, 2, 3,],
+  nested: {a: 1, /* inline */ b: [],},
+} // end
+`
+
+// go test -v -run Test_JsonParse_relaxed
+func Test_JsonParse_relaxed(t *testing.T) {
+	funName := "Test_JsonParse_relaxed"
+	testName := funName + "_json5"
+
+	root, errorsCollected := JsonParseWithOptions(srcJson5, Options{Relaxed: true})
+	compare_int_int(testName, 0, len(errorsCollected), t)
+	compare_str_str(testName, "web 'frontend'", root.ValObject["name"].ValRunes, t)
+	compare_int_int(testName, 31, root.ValObject["$id"].ValNumberInt, t)
+	compare_int_int(testName, -255, root.ValObject["_private"].ValNumberInt, t)
+	compare_str_str(testName, "double", root.ValObject["quoted"].ValRunes, t)
+	compare_flt_flt(testName, 0.5, root.ValObject["ratio"].ValNumberFloat, t)
+	compare_int_int(testName, 1, root.ValObject["plus"].ValNumberInt, t)
+	compare_bool_bool(testName, true, math.IsInf(root.ValObject["inf"].ValNumberFloat, 1), t)
+	compare_bool_bool(testName, true, math.IsInf(root.ValObject["negInf"].ValNumberFloat, -1), t)
+	compare_bool_bool(testName, true, math.IsNaN(root.ValObject["notNum"].ValNumberFloat), t)
+	compare_str_str(testName, "reserved word as key", root.ValObject["true"].ValRunes, t)
+	compare_str_str(testName, "[1,2,3]", root.ValObject["list"].Repr(), t)
+	compare_str_str(testName, `{"a":1,"b":[]}`, root.ValObject["nested"].Repr(), t)
+
+	testName = funName + "_round_trip" // Infinity and NaN are written as null, the output is valid JSON
+	root, _ = JsonParseWithOptions(`[Infinity, -Infinity, NaN, 1.5]`, Options{Relaxed: true})
+	compare_str_str(testName, `[null,null,null,1.5]`, root.Repr(), t)
+	rootBack, errorsCollected := JsonParse(root.Repr(2))
+	compare_int_int(testName, 0, len(errorsCollected), t)
+	compare_str_str(testName, root.Repr(), rootBack.Repr(), t)
+
+	testName = funName + "_escapes"
+	root, errorsCollected = JsonParseWithOptions(`['tab\vnul\0end', "\xe9\x41"]`, Options{Relaxed: true})
+	compare_int_int(testName, 0, len(errorsCollected), t)
+	compare_str_str(testName, "tab\vnul\x00end", root.ValArray[0].ValRunes, t)
+	compare_str_str(testName, "éA", root.ValArray[1].ValRunes, t)
+
+	testName = funName + "_strict_rejects_json5"
+	_, errorsCollected = JsonParse(srcJson5)
+	compare_bool_bool(testName, true, len(errorsCollected) > 0, t)
+
+	testName = funName + "_errors"
+	srcInvalids := map[string]error{
+		`{a: 1 /* unclosed`:  ErrUnclosedComment,
+		`[1, nope]`:          ErrInvalidLiteral,
+		`{"a": 1,, }`:        ErrMissingKey,
+		`{1a: 2}`:            ErrKeyNotString,
+		`{a: 'unclosed}`:     ErrUnclosedString,
+		`/* only comment */`: ErrEmptySrc,
+		`[0x]`:               ErrInvalidLiteral,
+		`[007]`:              ErrInvalidLiteral,
+		`["\01"]`:            ErrInvalidEscape,
+		`["\x4"]`:            ErrInvalidEscape,
+		`["\a"]`:             ErrInvalidEscape,
+	}
+	for src, kindWanted := range srcInvalids {
+		_, errorsCollected = JsonParseWithOptions(src, Options{Relaxed: true})
+		compare_bool_bool(testName+" "+src, true, len(errorsCollected) > 0 && errors.Is(errorsCollected[0], kindWanted), t)
+	}
+
+	testName = funName + "_comment_after_root"
+	_, errorsCollected = JsonParseWithOptions(`[1] // after root`, Options{Relaxed: true})
+	compare_int_int(testName, 0, len(errorsCollected), t)
+}
+
+// go test -v -run Test_stepA__tokensTableDetect_relaxed_L1
+func Test_stepA__tokensTableDetect_relaxed_L1(t *testing.T) {
+	funName := "Test_stepA__tokensTableDetect_relaxed_L1"
+	testName := funName + "_types"
+
+	tokens := stepA__tokensTableDetect_relaxed_L1([]byte(`{key:'v"x', // c
+"k2"/*b*/:-.5}`))
+	typesWanted := []rune{'{', 'i', ':', '"', ',', '/', '"', '/', ':', '0', '}'}
+	compare_int_int(testName, len(typesWanted), len(tokens), t)
+	for pos, typeWanted := range typesWanted {
+		compare_rune_rune(testName, typeWanted, tokens[pos].tokenType, t)
+	}
+	compare_int_int(testName, 5, tokens[3].posInSrcFirst, t) // 'v"x'
+	compare_int_int(testName, 9, tokens[3].posInSrcLast, t)
+	compare_int_int(testName, 15, tokens[5].posInSrcLast, t) // the newline is not in the comment
+
+	testName = funName + "_comments_remove"
+	compare_int_int(testName, len(typesWanted)-2, len(tokensTable_comments_remove(tokens)), t)
+
+	testName = funName + "_unclosed"
+	tokens = stepA__tokensTableDetect_relaxed_L1([]byte(`['abc`))
+	compare_rune_rune(testName, 'U', tokens[1].tokenType, t)
+	tokens = stepA__tokensTableDetect_relaxed_L1([]byte(`[1] /* abc`))
+	compare_rune_rune(testName, 'C', tokens[3].tokenType, t)
+}
+
+// go test -v -run Test_relaxed_literal_type
+func Test_relaxed_literal_type(t *testing.T) {
+	testName := "Test_relaxed_literal_type"
+	literals := map[string]rune{
+		"true": 't', "false": 'f', "null": 'n', "Infinity": '0', "NaN": '0', "+1": '0', ".5": '0', "0x1F": '0',
+		"-Infinity": '0', "name": 'i', "$id": 'i', "_a1": 'i', "árvíz": 'i', "#x": '?', "a-b": '?',
+	}
+	for literal, typeWanted := range literals {
+		compare_rune_rune(testName+" "+literal, typeWanted, relaxed_literal_type([]byte(literal)), t)
+	}
+}
+
+// go test -v -run Test_relaxed_string_close_find
+func Test_relaxed_string_close_find(t *testing.T) {
+	testName := "Test_relaxed_string_close_find"
+	compare_int_int(testName, 6, relaxed_string_close_find([]byte(`'a\'b"'`), 0), t)
+	compare_int_int(testName, 5, relaxed_string_close_find([]byte(`"a\\b" "`), 0), t)
+	compare_int_int(testName, -1, relaxed_string_close_find([]byte(`'abc\'`), 0), t)
+}
+
+// go test -v -run Test_relaxed_key_is_valid
+func Test_relaxed_key_is_valid(t *testing.T) {
+	testName := "Test_relaxed_key_is_valid"
+	src := []byte(`{"a" b 1 true}`)
+	tokens := stepA__tokensTableDetect_relaxed_L1(src)
+	compare_bool_bool(testName, true, relaxed_key_is_valid(src, tokens[1]), t)
+	compare_bool_bool(testName, true, relaxed_key_is_valid(src, tokens[2]), t)
+	compare_bool_bool(testName, false, relaxed_key_is_valid(src, tokens[3]), t)
+	compare_bool_bool(testName, true, relaxed_key_is_valid(src, tokens[4]), t)
+}
+
+// go test -v -run Test_numberValueParsing_textToNumber_relaxed_L2
+func Test_numberValueParsing_textToNumber_relaxed_L2(t *testing.T) {
+	funName := "Test_numberValueParsing_textToNumber_relaxed_L2"
+
+	testName := funName + "_ints"
+	ints := map[string]int{"0x1F": 31, "0XfF": 255, "-0x10": -16, "+0x10": 16, "+7": 7, "-7": -7}
+	for text, numWanted := range ints {
+		value, isNumber := numberValueParsing_textToNumber_relaxed_L2(text)
+		compare_bool_bool(testName+" "+text, true, isNumber, t)
+		compare_int_int(testName+" "+text, numWanted, value.ValNumberInt, t)
+	}
+
+	testName = funName + "_floats"
+	floats := map[string]float64{".5": 0.5, "5.": 5, "+1.5e2": 150, "-.25": -0.25}
+	for text, numWanted := range floats {
+		value, isNumber := numberValueParsing_textToNumber_relaxed_L2(text)
+		compare_bool_bool(testName+" "+text, true, isNumber, t)
+		compare_flt_flt(testName+" "+text, numWanted, value.ValNumberFloat, t)
+	}
+
+	testName = funName + "_invalids"
+	for _, text := range []string{"0x", "0xZZ", "+inf", "1_000", "0x1p-2", "--1", "+", ".", ".e5", "012", "1.2.3"} {
+		_, isNumber := numberValueParsing_textToNumber_relaxed_L2(text)
+		compare_bool_bool(testName+" "+text, false, isNumber, t)
+	}
+}
+
+// go test -v -run Test_relaxed_hexa2_to_intVal
+func Test_relaxed_hexa2_to_intVal(t *testing.T) {
+	testName := "Test_relaxed_hexa2_to_intVal"
+	value, isHexa := relaxed_hexa2_to_intVal([]byte(`\xe9`), 2)
+	compare_bool_bool(testName, true, isHexa, t)
+	compare_int_int(testName, 233, value, t)
+	_, isHexa = relaxed_hexa2_to_intVal([]byte(`\x9`), 2)
+	compare_bool_bool(testName, false, isHexa, t)
+}
@@ -8,15 +8,16 @@ LICENSE file in the root directory of this source tree.
 
 TODO, tests:
 non-closed string error detection
-incorrect numbers test (start with 0)
-incorrect atoms test (something else than true/false/null)
 */
 
 package jyp
 
 import (
+	"bytes"
 	"fmt"
 	"os"
+	"runtime"
+	"strings"
 	"testing"
 	"time"
 	"unicode/utf8"
@@ -132,29 +133,89 @@ func Test_speed(t *testing.T) {
 	// 	srcStr := strings.Repeat(srcEverything, 100)
 	// https://raw.githubusercontent.com/json-iterator/test-data/master/large-file.json
 
+	if _, err := os.Stat("large-file.json"); err != nil {
+		t.Skip("large-file.json is not downloaded, the speed test is skipped")
+	}
+
 	timeReadFileStart := time.Now()
-	srcStr := file_read_to_string("large-file.json")
-	srcRunes := []rune(srcStr)
-	fmt.Println("time read file to string:", time.Since(timeReadFileStart))
+	src := file_read_to_bytes("large-file.json")
+	fmt.Println("time read file to bytes:", time.Since(timeReadFileStart))
 
 	timeSimpleStringPassing := time.Now()
-	tokensTableB := stepA__tokensTableDetect_structuralTokens_strings_L1(srcRunes)
+	tokensTableB := stepA__tokensTableDetect_structuralTokens_strings_L1(src)
 	fmt.Println("time tokensTableDetect structuralTokens:", time.Since(timeSimpleStringPassing))
 
+	timeParallel := time.Now()
+	tokensTableParallel := Options{Parallel: runtime.NumCPU()}.tokensTableDetect(src)
+	fmt.Println("time tokensTableDetect parallel, goroutines:", runtime.NumCPU(), time.Since(timeParallel))
+	compare_int_int(testName, len(tokensTableB), len(tokensTableParallel), t)
+
 	timeStructure := time.Now()
-	errorsCollected := stepB__JSON_validation_L1(tokensTableB)
-	root, _ := stepC__JSON_structure_building__L1(srcRunes, tokensTableB, 0, errorsCollected)
+	errorsCollected := stepB__JSON_validation_L1(src, tokensTableB, Options{})
+	root, _ := stepC__JSON_structure_building__L1(src, tokensTableB, 0, errorsCollected, Options{})
 	fmt.Println("time structure:", time.Since(timeStructure))
 	_ = root
 
+	timeDocument := time.Now()
+	doc, _ := stepC__JSON_document_building__L1(src, tokensTableB)
+	fmt.Println("time document (tape) structure:", time.Since(timeDocument))
+	_ = doc
+
 	// python3 json.loads() speed: 0.24469351768493652 sec
 	// my speed: 3.82s (2024 Marc 16)
 	//           3.47s (2024 Marc 17)
 	//           1.24s (2024 Marc 25)
+	// with utf8 bytes instead of runes, the string -> []rune conversion is not necessary, see performance_notes.txt
 
 }
 
 
+// go test -run NONE -bench large_file -benchmem
+func benchmark_large_file_src(b *testing.B) []byte {
+	src, srcName := large_file_src_for_test()
+	b.Logf("src: %s, %d bytes", srcName, len(src))
+	b.ResetTimer()
+	return src
+}
+
+func BenchmarkTokensTable_large_file(b *testing.B) {
+	src := benchmark_large_file_src(b)
+	for i := 0; i < b.N; i++ {
+		_ = stepA__tokensTableDetect_structuralTokens_strings_L1(src)
+	}
+}
+
+// every chunk is tokenized twice, the wall time is smaller only if the goroutines get more cores
+func BenchmarkTokensTable_large_file_parallel(b *testing.B) {
+	src := benchmark_large_file_src(b)
+	for _, numOfGoroutines := range []int{2, 4} {
+		b.Run(fmt.Sprintf("goroutines_%d", numOfGoroutines), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_ = stepA__tokensTableDetect_parallel_L1(src, numOfGoroutines)
+			}
+		})
+	}
+}
+
+func BenchmarkJsonParse_large_file(b *testing.B) {
+	src := benchmark_large_file_src(b)
+	for i := 0; i < b.N; i++ {
+		_, _ = JsonParseBytes(src)
+	}
+}
+
+//  go test -v -run Test_large_file_standin_generate
+func Test_large_file_standin_generate(t *testing.T) {
+	testName := "Test_large_file_standin_generate"
+	src := large_file_standin_generate(50)
+	compare_str_str(testName+"_same_bytes", string(src), string(large_file_standin_generate(50)), t)
+
+	root, errorsCollected := JsonParseBytes(src)
+	compare_int_int(testName, 0, len(errorsCollected), t)
+	compare_int_int(testName, 50, len(root.ValArray), t)
+	compare_str_str(testName, "2489651045", root.ValArray[0].ValObject["id"].ValRunes, t)
+}
+
 
 
 //////////////////////////// TEST BASE FUNCS ///////////////////
@@ -214,6 +275,14 @@ func file_read_to_string(fn string) string {
 	return string(dat)
 }
 
+func file_read_to_bytes(fn string) []byte {
+	dat, err := os.ReadFile(fn)
+	if err != nil {
+		panic(err)
+	}
+	return dat
+}
+
 func file_read_to_runes(fn string) []rune {
 	bytes, err := os.ReadFile(fn)
 	if err != nil { panic(err) }
@@ -226,3 +295,69 @@ func file_read_to_runes(fn string) []rune {
 	}
 	return runes
 }
+
+
+// large-file.json: https://raw.githubusercontent.com/json-iterator/test-data/master/large-file.json
+// (26Mb, an array of 11351 github events). If it is not downloaded, the benchmarks
+// use a generated stand-in with the same structure, see performance_notes.txt
+func large_file_src_for_test() ([]byte, string) {
+	if _, err := os.Stat("large-file.json"); err == nil {
+		return file_read_to_bytes("large-file.json"), "large-file.json"
+	}
+	return large_file_standin_generate(11351), "generated stand-in"
+}
+
+// github events, like in large-file.json. There is no randomness: the same
+// numOfEvents gives the same bytes on every machine, so the measurements can be repeated
+func large_file_standin_generate(numOfEvents int) []byte { // TESTED
+	eventTypes := []string{"PushEvent", "CreateEvent", "WatchEvent", "IssuesEvent", "PullRequestEvent", "ForkEvent"}
+	words := []string{"fix", "the", "parser", "update", "README.md", "add", "tests", "for", "unicode", "árvíztűrő", "tükörfúrógép", "refactor", "build", "\\\"quoted\\\"", "line\\nbreak", "merge", "branch", "release", "v1.2.3", "docs"}
+	seed := uint32(1)
+	next := func(limit int) int { // linear congruential generator, fixed seed
+		seed = seed*1664525 + 1013904223
+		return int(seed>>8) % limit
+	}
+	sentence := func(numOfWords int) string {
+		wordsSelected := []string{}
+		for counter := 0; counter < numOfWords; counter++ {
+			wordsSelected = append(wordsSelected, words[next(len(words))])
+		}
+		return strings.Join(wordsSelected, " ")
+	}
+	separator := func(position int) string { // no comma before the first elem
+		if position == 0 {
+			return ""
+		}
+		return ","
+	}
+
+	out := bytes.Buffer{}
+	out.WriteString("[")
+	for eventId := 0; eventId < numOfEvents; eventId++ {
+		actorId, repoId := 100000+next(900000), 1000000+next(9000000)
+		login := fmt.Sprintf("user%d", actorId)
+		repoName := fmt.Sprintf("%s/repo-%d", login, repoId)
+		eventType := eventTypes[next(len(eventTypes))]
+		fmt.Fprintf(&out, "%s{\"id\":\"%d\",\"type\":\"%s\",", separator(eventId), 2489651045+eventId, eventType)
+		fmt.Fprintf(&out, "\"actor\":{\"id\":%d,\"login\":\"%s\",\"gravatar_id\":\"\",\"url\":\"https://api.github.com/users/%s\",\"avatar_url\":\"https://avatars.githubusercontent.com/u/%d?\"},", actorId, login, login, actorId)
+		fmt.Fprintf(&out, "\"repo\":{\"id\":%d,\"name\":\"%s\",\"url\":\"https://api.github.com/repos/%s\"},", repoId, repoName, repoName)
+
+		out.WriteString("\"payload\":{")
+		if eventType == "PushEvent" {
+			numOfCommits := 1 + next(8)
+			fmt.Fprintf(&out, "\"push_id\":%d,\"size\":%d,\"distinct_size\":%d,\"ref\":\"refs/heads/master\",\"head\":\"%040x\",\"before\":\"%040x\",\"commits\":[", 536863970+eventId, numOfCommits, numOfCommits, next(1<<24), next(1<<24))
+			for commitId := 0; commitId < numOfCommits; commitId++ {
+				sha := fmt.Sprintf("%040x", next(1<<24))
+				fmt.Fprintf(&out, "%s{\"sha\":\"%s\",\"author\":{\"email\":\"%s@example.com\",\"name\":\"%s\"},\"message\":\"%s\",\"distinct\":%t,\"url\":\"https://api.github.com/repos/%s/commits/%s\"}",
+					separator(commitId), sha, login, login, sentence(5+next(90)), next(4) > 0, repoName, sha)
+			}
+			out.WriteString("]")
+		} else {
+			fmt.Fprintf(&out, "\"ref\":\"master\",\"ref_type\":\"branch\",\"master_branch\":\"master\",\"description\":\"%s\",\"pusher_type\":\"user\",\"number\":%d,\"score\":%d.%d,\"labels\":[\"bug\",\"help wanted\"],\"merged\":null,\"body\":\"%s\"",
+				sentence(3+next(20)), next(5000), next(100), next(100), sentence(next(380)))
+		}
+		fmt.Fprintf(&out, "},\"public\":true,\"created_at\":\"2015-01-01T15:%02d:%02dZ\"}", next(60), next(60))
+	}
+	out.WriteString("]")
+	return out.Bytes()
+}
@@ -12,6 +12,7 @@ LICENSE file in the root directory of this source tree.
 package jyp
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 )
@@ -22,7 +23,7 @@ func Test_stepA__tokensTableDetect_structuralTokens_strings_L1(t *testing.T) {
 	testName := funName + "_basic"
 
 	// src = `{"a": "b"}`
-	src := []rune(`{"a": "A", "b1": {"b2":"B2"}, "c":"C", "list":["k", "bh"]}`)
+	src := []byte(`{"a": "A", "b1": {"b2":"B2"}, "c":"C", "list":["k", "bh"]}`)
 	tokensTableB := stepA__tokensTableDetect_structuralTokens_strings_L1(src)
 	tokenA := tokensTableB[3]
 	// "A"
@@ -40,25 +41,128 @@ func Test_stepA__tokensTableDetect_structuralTokens_strings_L1(t *testing.T) {
 }
 
 
+//  go test -v -run  Test_stepA__tokensTableDetect_src_end
+func Test_stepA__tokensTableDetect_src_end(t *testing.T) {
+	funName := "Test_stepA__tokensTableDetect_src_end"
+	testName := funName + "_number_at_end"
+
+	tokens := stepA__tokensTableDetect_structuralTokens_strings_L1([]byte(`42`))
+	compare_int_int(testName, 1, len(tokens), t)
+	compare_rune_rune(testName, '0', tokens[0].tokenType, t)
+	compare_int_int(testName, 1, tokens[0].posInSrcLast, t)
+
+	testName = funName + "_invalid_literal"
+	tokens = stepA__tokensTableDetect_structuralTokens_strings_L1([]byte(`[tru]`))
+	compare_rune_rune(testName, '?', tokens[1].tokenType, t)
+
+	testName = funName + "_unclosed_string"
+	tokens = stepA__tokensTableDetect_structuralTokens_strings_L1([]byte(`["abc`))
+	compare_int_int(testName, 2, len(tokens), t)
+	compare_rune_rune(testName, 'U', tokens[1].tokenType, t)
+	compare_int_int(testName, 4, tokens[1].posInSrcLast, t)
+
+	testName = funName + "_quote_after_literal"
+	tokens = stepA__tokensTableDetect_structuralTokens_strings_L1([]byte(`[abc"x y"]`))
+	compare_int_int(testName, 4, len(tokens), t)
+	compare_rune_rune(testName, '?', tokens[1].tokenType, t)
+	compare_int_int(testName, 3, tokens[1].posInSrcLast, t)
+	compare_rune_rune(testName, '"', tokens[2].tokenType, t)
+}
+
+
+//  go test -v -run  Test_stepB__JSON_validation_L1
+func Test_stepB__JSON_validation_L1(t *testing.T) {
+	funName := "Test_stepB__JSON_validation_L1"
+
+	srcValids := []string{
+		`{}`, `[]`, `"str"`, `42`, `-1.5e3`, `true`, `null`, `0`, `-0.0`, `0e1`, `1E-2`,
+		`{"a": 1, "b": [true, false, null], "c": {"d": "D"}}`,
+		`[[], {}, [{}], {"e": []}]`,
+	}
+	for _, src := range srcValids {
+		testName := funName + "_valid: " + src
+		srcBytes := []byte(src)
+		errorsCollected := stepB__JSON_validation_L1(srcBytes, stepA__tokensTableDetect_structuralTokens_strings_L1(srcBytes), Options{})
+		compare_int_int(testName, 0, len(errorsCollected), t)
+	}
+
+	type srcWithError struct {
+		src       string
+		errKind   error
+		errOffset int
+	}
+	srcInvalids := []srcWithError{
+		{``,          ErrEmptySrc,          0},
+		{`{"a" 1}`,   ErrMissingColon,      5},
+		{`[1 2]`,     ErrMissingComma,      3},
+		{`{"a":1,}`,  ErrTrailingComma,     7},
+		{`[1,]`,      ErrTrailingComma,     3},
+		{`{,}`,       ErrMissingKey,        1},
+		{`{1: 2}`,    ErrKeyNotString,      1},
+		{`{"a":}`,    ErrMissingValue,      5},
+		{`[,1]`,      ErrMissingValue,      1},
+		{`tru`,       ErrInvalidLiteral,    0},
+		{`["abc`,     ErrUnclosedString,    1},
+		{`[1] [2]`,   ErrUnexpectedToken,   4},
+		{`{"a": 1]`,  ErrUnpairedCloser,    7},
+		{`}`,         ErrUnpairedCloser,    0},
+		{`[{"a": 1}`, ErrUnclosedContainer, 0},
+		{`[1, 012]`,  ErrInvalidLiteral,    4}, // leading zero
+		{`-01`,       ErrInvalidLiteral,    0},
+		{`[0x1f]`,    ErrInvalidLiteral,    1},
+		{`1.`,        ErrInvalidLiteral,    0},
+		{`[.5]`,      ErrInvalidLiteral,    1},
+		{`+1`,        ErrInvalidLiteral,    0},
+		{`1e`,        ErrInvalidLiteral,    0},
+		{`-`,         ErrInvalidLiteral,    0},
+		{`[1.5.2]`,   ErrInvalidLiteral,    1},
+		{`[nul]`,     ErrInvalidLiteral,    1},
+		{`[True]`,    ErrInvalidLiteral,    1},
+		{`[1e400, 2]`,    ErrInvalidLiteral, 1}, // out of the float64 range
+		{`{"a": -1e999}`, ErrInvalidLiteral, 6},
+		{"\vnull",        ErrInvalidLiteral, 0}, // \v and \f are not JSON whitespaces
+		{"[1,\f2]",       ErrInvalidLiteral, 3},
+	}
+	for _, srcInvalid := range srcInvalids {
+		testName := funName + "_invalid: " + srcInvalid.src
+		srcBytes := []byte(srcInvalid.src)
+		errorsCollected := stepB__JSON_validation_L1(srcBytes, stepA__tokensTableDetect_structuralTokens_strings_L1(srcBytes), Options{})
+		if len(errorsCollected) == 0 { // errorsCollected[0] is used below
+			t.Fatalf("\nError in %s: the invalid src is not detected", testName)
+		}
+		compare_bool_bool(testName, true, errors.Is(errorsCollected[0], srcInvalid.errKind), t)
+
+		var parseErr ParseError
+		compare_bool_bool(testName, true, errors.As(errorsCollected[0], &parseErr), t)
+		compare_int_int(testName, srcInvalid.errOffset, parseErr.Offset, t)
+	}
+
+	testName := funName + "_more_errors_reported"
+	srcBytes := []byte(`[1 2, {"a" 3}, nul]`)
+	errorsCollected := stepB__JSON_validation_L1(srcBytes, stepA__tokensTableDetect_structuralTokens_strings_L1(srcBytes), Options{})
+	compare_int_int(testName, 3, len(errorsCollected), t)
+}
+
+
 //  go test -v -run  Test_structure_building
 func Test_structure_building(t *testing.T) {
 	funName := "Test_structure_building"
 	testName := funName + "_basic_obj"
 	errorsCollected := []error{}
 
-	src := []rune(`{"a": "A"}`)
+	src := []byte(`{"a": "A"}`)
 	tokensTableB := stepA__tokensTableDetect_structuralTokens_strings_L1(src)
-	errorsCollected = stepB__JSON_validation_L1(tokensTableB)
-	root, _ := stepC__JSON_structure_building__L1(src, tokensTableB, 0, errorsCollected)
+	errorsCollected = stepB__JSON_validation_L1(src, tokensTableB, Options{})
+	root, _ := stepC__JSON_structure_building__L1(src, tokensTableB, 0, errorsCollected, Options{})
 	compare_rune_rune(testName, '{', root.ValType, t)
 	compare_int_int(testName, 1, len(root.ValObject), t) // has 1 elem
 	compare_str_str(testName, "A", root.ValObject["a"].ValRunes, t)
 
 	testName = funName + "_basic_arr"
-	src = []rune(`["a", "A"]`)
+	src = []byte(`["a", "A"]`)
 	tokensTableB = stepA__tokensTableDetect_structuralTokens_strings_L1(src)
-	errorsCollected = stepB__JSON_validation_L1(tokensTableB)
-	root, _ = stepC__JSON_structure_building__L1(src, tokensTableB, 0, errorsCollected)
+	errorsCollected = stepB__JSON_validation_L1(src, tokensTableB, Options{})
+	root, _ = stepC__JSON_structure_building__L1(src, tokensTableB, 0, errorsCollected, Options{})
 	compare_rune_rune(testName, '[', root.ValType, t)
 	compare_int_int(testName, 2, len(root.ValArray), t)          // has 1 elem
 	compare_str_str(testName, "a", root.ValArray[0].ValRunes, t) // has 1 elem
@@ -72,11 +176,11 @@ func Test_structure_building_complex(t *testing.T) {
 	testName := funName + "_base"
 	errorsCollected := []error{}
 
-	src := []rune(`{"a": "A", "arr": ["0", "1", "2"], "obj": {"key": ["val"]} }`)
+	src := []byte(`{"a": "A", "arr": ["0", "1", "2"], "obj": {"key": ["val"]} }`)
 	tokensTableB := stepA__tokensTableDetect_structuralTokens_strings_L1(src)
 	tokensTableB.print()
-	errorsCollected = stepB__JSON_validation_L1(tokensTableB)
-	root, _ := stepC__JSON_structure_building__L1(src, tokensTableB, 0, errorsCollected)
+	errorsCollected = stepB__JSON_validation_L1(src, tokensTableB, Options{})
+	root, _ := stepC__JSON_structure_building__L1(src, tokensTableB, 0, errorsCollected, Options{})
 	fmt.Println(root.Repr())
 	compare_rune_rune(testName, '{', root.ValType, t)
 	compare_int_int(testName, 3, len(root.ValObject), t) // has 1 elem
@@ -104,12 +208,12 @@ func Test_numbers_int(t *testing.T) {
 	testName := funName + "_base"
 	errorsCollected := []error{}
 
-	src := []rune(`{"age": -123, "favouriteNums": [4, 5, 6] }`)
+	src := []byte(`{"age": -123, "favouriteNums": [4, 5, 6] }`)
 	tokensTableB := stepA__tokensTableDetect_structuralTokens_strings_L1(src)
 	tokensTableB.print()
 
-	errorsCollected = stepB__JSON_validation_L1(tokensTableB)
-	root, _ := stepC__JSON_structure_building__L1(src, tokensTableB, 0, errorsCollected)
+	errorsCollected = stepB__JSON_validation_L1(src, tokensTableB, Options{})
+	root, _ := stepC__JSON_structure_building__L1(src, tokensTableB, 0, errorsCollected, Options{})
 	fmt.Println(root.Repr())
 
 	compare_rune_rune(testName, '{', root.ValType, t)
@@ -130,12 +234,12 @@ func Test_numbers_float(t *testing.T) {
 	testName := funName + "_base"
 	errorsCollected := []error{}
 
-	src := []rune(`{"celsiusDegrees": [0.12, -3.45, 6.789] }`)
+	src := []byte(`{"celsiusDegrees": [0.12, -3.45, 6.789] }`)
 	tokensTableB := stepA__tokensTableDetect_structuralTokens_strings_L1(src)
 	tokensTableB.print()
 
-	errorsCollected = stepB__JSON_validation_L1(tokensTableB)
-	root, _ := stepC__JSON_structure_building__L1(src, tokensTableB, 0, errorsCollected)
+	errorsCollected = stepB__JSON_validation_L1(src, tokensTableB, Options{})
+	root, _ := stepC__JSON_structure_building__L1(src, tokensTableB, 0, errorsCollected, Options{})
 	fmt.Println(root.Repr())
 
 	compare_rune_rune(testName, '{', root.ValType, t)
@@ -152,12 +256,12 @@ func Test_true_false_null(t *testing.T) {
 	testName := funName + "_base"
 	errorsCollected := []error{}
 
-	src := []rune(`{"atoms": [true, false, null] }`)
+	src := []byte(`{"atoms": [true, false, null] }`)
 	tokensTableB := stepA__tokensTableDetect_structuralTokens_strings_L1(src)
 	tokensTableB.print()
 
-	errorsCollected = stepB__JSON_validation_L1(tokensTableB)
-	root, _ := stepC__JSON_structure_building__L1(src, tokensTableB, 0, errorsCollected)
+	errorsCollected = stepB__JSON_validation_L1(src, tokensTableB, Options{})
+	root, _ := stepC__JSON_structure_building__L1(src, tokensTableB, 0, errorsCollected, Options{})
 	fmt.Println(root.Repr())
 
 	compare_rune_rune(testName, '{', root.ValType, t)
@@ -175,7 +279,7 @@ func Test_token_find_next__L2(t *testing.T) {
 	funName := "Test_token_find_next__L2"
 	testName := funName + "_base"
 	_ = testName
-	src := []rune(`{"a": "A", "l": [4, 5, 6], "end": "E", "num": 42}`)
+	src := []byte(`{"a": "A", "l": [4, 5, 6], "end": "E", "num": 42}`)
 
 	tokensTable := stepA__tokensTableDetect_structuralTokens_strings_L1(src)
 
@@ -203,14 +307,33 @@ func Test_token_find_next__L2(t *testing.T) {
 
 
 
+// go test -v -run Test_numberValueParsing_textToNumber_L2
+func Test_numberValueParsing_textToNumber_L2(t *testing.T) {
+	funName := "Test_numberValueParsing_textToNumber_L2"
+	testName := funName + "_base"
+
+	value, isNumber := numberValueParsing_textToNumber_L2("-12")
+	compare_bool_bool(testName, true, isNumber, t)
+	compare_rune_rune(testName, 'I', value.ValType, t)
+	compare_int_int(testName, -12, value.ValNumberInt, t)
+
+	value, isNumber = numberValueParsing_textToNumber_L2("1.5e2")
+	compare_bool_bool(testName, true, isNumber, t)
+	compare_rune_rune(testName, 'F', value.ValType, t)
+	compare_flt_flt(testName, 150, value.ValNumberFloat, t)
+
+	_, isNumber = numberValueParsing_textToNumber_L2("1x")
+	compare_bool_bool(testName, false, isNumber, t)
+}
+
+
 // go test -v -run Test_stringValueParsing_rawToInterpretedCharacters_L2
 func Test_stringValueParsing_rawToInterpretedCharacters_L2(t *testing.T) {
 	funName := "Test_stringValueParsing_rawToInterpretedCharacters_L2"
 	testName := funName + "_base"
-	errorsCollected := []error{}
 
-	src := []rune(`backQuote:\",backBack:\\,backForward:\/,backB:\b,backF:\f,newline:\n,cr:\r,tab:\t,B:\u0042`)
-	textInterpreted :=  stringValueParsing_rawToInterpretedCharacters_L2(src, errorsCollected)
+	src := []byte(`backQuote:\",backBack:\\,backForward:\/,backB:\b,backF:\f,newline:\n,cr:\r,tab:\t,B:\u0042`)
+	textInterpreted :=  stringValueParsing_rawToInterpretedCharacters_L2(src)
 	fmt.Println("text interpreted:", textInterpreted)
 	textRunes := []rune(textInterpreted)
 	compare_rune_rune(testName, '"',  textRunes[10], t)
@@ -224,4 +347,93 @@ func Test_stringValueParsing_rawToInterpretedCharacters_L2(t *testing.T) {
 	compare_rune_rune(testName, '\t', textRunes[72], t)
 	compare_rune_rune(testName, 'B',  textRunes[76], t)
 
+	testName = funName + "_surrogate_pairs"
+	compare_str_str(testName, "😀 é", stringValueParsing_rawToInterpretedCharacters_L2([]byte(`\ud83d\uDE00 \u00E9`)), t)
+	compare_str_str(testName, "\uFFFD-\uFFFD", stringValueParsing_rawToInterpretedCharacters_L2([]byte(`\ud83d-\ude00`)), t) // lone surrogates
+	compare_str_str(testName, "\uFFFDA", stringValueParsing_rawToInterpretedCharacters_L2([]byte(`\ud83d\u0041`)), t)
+
+	testName = funName + "_not_validated"
+	compare_str_str(testName, `a\x \u12`, stringValueParsing_rawToInterpretedCharacters_L2([]byte(`a\x \u12`)), t)
+	compare_str_str(testName, `end\`, stringValueParsing_rawToInterpretedCharacters_L2([]byte(`end\`)), t)
+}
+
+// go test -v -run Test_stringValueRepr_interpretedToRaw_L2
+func Test_stringValueRepr_interpretedToRaw_L2(t *testing.T) {
+	funName := "Test_stringValueRepr_interpretedToRaw_L2"
+
+	testName := funName + "_base"
+	compare_str_str(testName, `"simple"`, stringValueRepr_interpretedToRaw_L2("simple", false, false), t)
+	compare_str_str(testName, `"q\"b\\n\nr\rt\tb\bf\f\u0001\u001f"`, stringValueRepr_interpretedToRaw_L2("q\"b\\n\nr\rt\tb\bf\f\x01\x1f", false, false), t)
+	compare_str_str(testName, `"é😀<&>"`, stringValueRepr_interpretedToRaw_L2("é😀<&>", false, false), t)
+	compare_str_str(testName, "\"a\uFFFDb\"", stringValueRepr_interpretedToRaw_L2("a\xffb", false, false), t) // invalid utf8
+
+	testName = funName + "_ensure_ascii"
+	compare_str_str(testName, `"\u00e9\ud83d\ude00<"`, stringValueRepr_interpretedToRaw_L2("é😀<", true, false), t)
+
+	testName = funName + "_html_safe"
+	compare_str_str(testName, `"\u003c/script\u003e \u0026 \u2028\u2029 é"`, stringValueRepr_interpretedToRaw_L2("</script> & \u2028\u2029 é", false, true), t)
+}
+
+// go test -v -run Test_stringValueParsing_escapes_check_L2
+func Test_stringValueParsing_escapes_check_L2(t *testing.T) {
+	funName := "Test_stringValueParsing_escapes_check_L2"
+	testName := funName + "_base"
+
+	posInvalid, kind := stringValueParsing_escapes_check_L2([]byte(`a\"\\\/\b\f\n\r\t\u00Af`), false)
+	compare_int_int(testName, -1, posInvalid, t)
+	compare_bool_bool(testName, true, kind == nil, t)
+
+	posInvalid, kind = stringValueParsing_escapes_check_L2([]byte(`ab\x`), false)
+	compare_int_int(testName, 2, posInvalid, t)
+	compare_bool_bool(testName, true, kind == ErrInvalidEscape, t)
+
+	posInvalid, kind = stringValueParsing_escapes_check_L2([]byte(`\u12g4`), false)
+	compare_int_int(testName, 0, posInvalid, t)
+	compare_bool_bool(testName, true, kind == ErrInvalidEscape, t)
+
+	posInvalid, _ = stringValueParsing_escapes_check_L2([]byte(`end\`), false)
+	compare_int_int(testName, 3, posInvalid, t)
+
+	posInvalid, kind = stringValueParsing_escapes_check_L2([]byte("a\tb"), false)
+	compare_int_int(testName, 1, posInvalid, t)
+	compare_bool_bool(testName, true, kind == ErrControlCharacter, t)
+
+	testName = funName + "_relaxed"
+	posInvalid, _ = stringValueParsing_escapes_check_L2([]byte("it\\'s\ta"), true)
+	compare_int_int(testName, -1, posInvalid, t)
+	posInvalid, _ = stringValueParsing_escapes_check_L2([]byte(`it\'s`), false)
+	compare_int_int(testName, 2, posInvalid, t)
+	posInvalid, kind = stringValueParsing_escapes_check_L2([]byte("a\nb"), true)
+	compare_int_int(testName, 1, posInvalid, t)
+	compare_bool_bool(testName, true, kind == ErrControlCharacter, t)
+	posInvalid, _ = stringValueParsing_escapes_check_L2([]byte(`\v\0.\x7F`), true)
+	compare_int_int(testName, -1, posInvalid, t)
+	posInvalid, _ = stringValueParsing_escapes_check_L2([]byte(`a\v`), false) // JSON5 escapes only in relaxed mode
+	compare_int_int(testName, 1, posInvalid, t)
+	posInvalid, _ = stringValueParsing_escapes_check_L2([]byte(`a\01`), true)
+	compare_int_int(testName, 1, posInvalid, t)
+}
+
+//  go test -v -run Test_ValObject_keys_ordered
+func Test_ValObject_keys_ordered(t *testing.T) {
+	funName := "Test_ValObject_keys_ordered"
+	testName := funName + "_parsed"
+
+	root, errorsCollected := JsonParse(`{"z": 1, "a": {"y": 2, "b": 3}, "m": 4}`)
+	compare_int_int(testName, 0, len(errorsCollected), t)
+	compare_str_str(testName, "z a m", fmt.Sprint(root.ValObject_keys_ordered())[1:6], t)
+	compare_str_str(testName, "[y b]", fmt.Sprint(root.ValObject["a"].ValObject_keys_ordered()), t)
+
+	testName = funName + "_direct_map_write"
+	root.ValObject["c"] = NewNull() // without AddKeyVal: at the end, sorted
+	root.ValObject["b"] = NewNull()
+	compare_str_str(testName, "[z a m b c]", fmt.Sprint(root.ValObject_keys_ordered()), t)
+
+	testName = funName + "_duplicates_collect"
+	root, _ = JsonParseWithOptions(`{"k": 1, "j": 2, "k": 3}`, Options{DuplicateKeys: DuplicateKeysCollect})
+	compare_str_str(testName, "[k j]", fmt.Sprint(root.ValObject_keys_ordered()), t)
+
+	testName = funName + "_without_order_map"
+	value := JSON_value{ValType: '{', ValObject: map[string]JSON_value{"b": NewNull(), "a": NewNull()}}
+	compare_str_str(testName, "[a b]", fmt.Sprint(value.ValObject_keys_ordered()), t)
 }
@@ -0,0 +1,1120 @@
+/*
+Copyright (c) 2024, Balazs Nyiro, balazs.nyiro.ca@gmail.com
+All rights reserved.
+
+This source code (all file in this repo) is licensed
+under the Apache-2 style license found in the
+LICENSE file in the root directory of this source tree.
+
+This module: YAML parsing into JSON_value, and YAML output of JSON_value.
+
+	root, errs := jyp.YamlParse(src)       // the first document
+	docs, errs := jyp.YamlParseAll(src)    // every document of a --- separated stream
+	yamlSrc := root.ReprYaml(jyp.YamlOptions{Indent: 2, FlowArrayMaxLen: 60})
+
+YAML 1.2, core schema:
+  - block mappings and sequences (nested with indentation), compact "- key: value" items,
+  - flow mappings and sequences: {a: 1, b: [x, y]}, they can be multi-line,
+  - plain, 'single quoted' and "double quoted" scalars, multi-line scalars are folded,
+  - literal | and folded > block scalars, with chomping (- +) and indentation indicators,
+  - anchors (&name) and aliases (*name), the alias is a copy of the anchored value,
+  - tags: !!str, !!int, !!float, !!bool, !!null are applied, other tags are ignored,
+  - multiple documents: --- and ... markers, % directives are skipped.
+
+Plain scalars are resolved with the core schema: null ~ true false, ints (decimal, 0x, 0o),
+floats (.inf, .nan too), everything else is a string. Quoted and block scalars are always strings.
+Object keys are strings in JSON_value, so a non-string key is used with its text: 1: a -> "1".
+
+Not supported: complex keys (? key), merge keys (<<), anchors on keys. A complex key is an ErrYamlSyntax.
+
+ReprYaml writes block style YAML, the object keys are sorted (or in insertion order, with
+KeysInsertionOrder). A string is quoted if it would be read back as something else (true, 1.0, ~,
+and the YAML 1.1 yes/no/on/off too), multi-line and long strings are written as literal block scalars.
+
+The src is processed in lines: every line knows its indentation. The value after
+"key: " or "- " is parsed as a virtual line, that starts at the column of the value.
+*/
+
+package jyp
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	ErrYamlIndentation  = errors.New("yaml: bad indentation")
+	ErrYamlUnknownAlias = errors.New("yaml: unknown alias")
+	ErrYamlSyntax       = errors.New("yaml: syntax error")
+)
+
+type yamlLine struct {
+	indent   int    // num of leading spaces. In a virtual line, the column of the text
+	text     string // the line without indentation and line end
+	posInSrc int    // the byte position of text in src
+	inline   bool   // virtual line after "key: " - a mapping or a sequence cannot start here
+}
+
+type yamlParser struct {
+	src             []byte
+	lines           []yamlLine
+	pos             int // the actual line
+	anchors         map[string]JSON_value
+	tag             string // the tag of the actual node, it is applied on the next scalar
+	errorsCollected []error
+}
+
+// the first document of the src. An empty src is a null document
+func YamlParse(srcStr string) (JSON_value, []error) {
+	docs, errorsCollected := YamlParseAll(srcStr)
+	if len(docs) == 0 {
+		return NewNull(), errorsCollected
+	}
+	return docs[0], errorsCollected
+}
+
+func YamlParseAll(srcStr string) ([]JSON_value, []error) {
+	src := []byte(srcStr)
+	docs := []JSON_value{}
+	errorsCollected := []error{}
+	for _, docLines := range yaml_documents_split(src, yaml_lines_split(src)) {
+		parser := yamlParser{src: src, lines: docLines, anchors: map[string]JSON_value{}}
+		docs = append(docs, parser.document_parse())
+		errorsCollected = append(errorsCollected, parser.errorsCollected...)
+	}
+	return docs, errorsCollected
+}
+
+func yaml_lines_split(src []byte) []yamlLine { // TESTED
+	lines := []yamlLine{}
+	for posLineStart := 0; posLineStart < len(src); {
+		posLineEnd := posLineStart
+		for posLineEnd < len(src) && src[posLineEnd] != '\n' {
+			posLineEnd++
+		}
+		textEnd := posLineEnd
+		if textEnd > posLineStart && src[textEnd-1] == '\r' {
+			textEnd--
+		}
+		indent := 0
+		for posLineStart+indent < textEnd && src[posLineStart+indent] == ' ' {
+			indent++
+		}
+		lines = append(lines, yamlLine{indent: indent, text: string(src[posLineStart+indent : textEnd]), posInSrc: posLineStart + indent})
+		posLineStart = posLineEnd + 1
+	}
+	return lines
+}
+
+// the lines of the documents, without the markers. A document without content is not returned,
+// only if it was explicitly started with ---
+func yaml_documents_split(src []byte, lines []yamlLine) [][]yamlLine { // TESTED
+	docs := [][]yamlLine{}
+	docLines := []yamlLine{}
+	docStarted := false // explicit --- or any content
+	docClose := func() {
+		if docStarted {
+			docs = append(docs, docLines)
+		}
+		docLines = []yamlLine{}
+		docStarted = false
+	}
+
+	for _, line := range lines {
+		isMarker := func(marker string) bool {
+			return line.indent == 0 && strings.HasPrefix(line.text, marker) && (len(line.text) == 3 || line.text[3] == ' ' || line.text[3] == '\t')
+		}
+		if isMarker("---") {
+			if docStarted {
+				docClose()
+			}
+			docStarted = true
+			rest := strings.TrimLeft(line.text[3:], " \t")
+			if rest != "" { // --- value
+				offset := len(line.text) - len(rest)
+				docLines = append(docLines, yamlLine{indent: offset, text: rest, posInSrc: line.posInSrc + offset})
+			}
+		} else if isMarker("...") {
+			docClose()
+		} else if line.indent == 0 && strings.HasPrefix(line.text, "%") && !docStarted {
+			// directive, %YAML 1.2 for example
+		} else {
+			if !yaml_line_is_blank(line) {
+				docStarted = true
+			}
+			docLines = append(docLines, line)
+		}
+	}
+	docClose()
+	return docs
+}
+
+// empty, or comment only
+func yaml_line_is_blank(line yamlLine) bool { // TESTED
+	text := strings.TrimLeft(line.text, " \t")
+	return text == "" || text[0] == '#'
+}
+
+func (p *yamlParser) errAdd(posInSrc int, kind error, msg string) {
+	p.errorsCollected = append(p.errorsCollected, newParseError(p.src, posInSrc, '?', kind, msg))
+}
+
+func (p *yamlParser) blank_lines_skip() {
+	for p.pos < len(p.lines) && yaml_line_is_blank(p.lines[p.pos]) {
+		p.pos++
+	}
+}
+
+func (p *yamlParser) document_parse() JSON_value {
+	value := p.block_node_parse(-1)
+	p.blank_lines_skip()
+	if p.pos < len(p.lines) {
+		p.errAdd(p.lines[p.pos].posInSrc, ErrYamlIndentation, "unexpected content after the root node")
+	}
+	return value
+}
+
+// the actual line is replaced with the part that starts from the offset
+func (p *yamlParser) line_virtual_set(offset int, inline bool) {
+	line := p.lines[p.pos]
+	p.lines[p.pos] = yamlLine{indent: line.indent + offset, text: line.text[offset:], posInSrc: line.posInSrc + offset, inline: inline}
+}
+
+// a node that is more indented than the parent
+func (p *yamlParser) block_node_parse(parentIndent int) JSON_value {
+	p.blank_lines_skip()
+	if p.pos >= len(p.lines) || p.lines[p.pos].indent <= parentIndent {
+		return NewNull() // empty node
+	}
+	line := p.lines[p.pos]
+	if strings.HasPrefix(line.text, "\t") {
+		p.errAdd(line.posInSrc, ErrYamlIndentation, "tab is not allowed in indentation")
+	}
+
+	anchor, tag, offset := yaml_properties_read(line.text)
+	if offset > 0 {
+		p.tag = tag
+		if yaml_line_is_blank(yamlLine{text: line.text[offset:]}) { // the node is in the next lines
+			p.pos++
+		} else {
+			p.line_virtual_set(offset, line.inline)
+		}
+		value := p.block_node_parse(parentIndent)
+		p.tag = ""
+		if anchor != "" {
+			p.anchors[anchor] = value
+		}
+		return value
+	}
+
+	text := line.text
+	if strings.HasPrefix(text, "*") {
+		return p.alias_parse(line)
+	}
+	if !line.inline && (text == "-" || strings.HasPrefix(text, "- ") || strings.HasPrefix(text, "-\t")) {
+		p.tag = "" // the tags of the collections are not used
+		return p.block_sequence_parse(line.indent)
+	}
+	if !line.inline {
+		if _, _, isEntry := yaml_mapping_entry_split(text); isEntry {
+			p.tag = ""
+			return p.block_mapping_parse(line.indent)
+		}
+	}
+	if text[0] == '[' || text[0] == '{' {
+		value, posEnd := p.flow_node_parse(line.posInSrc)
+		p.line_rest_check(posEnd)
+		return value
+	}
+	if text[0] == '|' || text[0] == '>' {
+		return p.scalar_value(p.block_scalar_parse(parentIndent), false, line.posInSrc)
+	}
+	if text == "?" || text == ":" || strings.HasPrefix(text, "? ") || strings.HasPrefix(text, ": ") {
+		p.errAdd(line.posInSrc, ErrYamlSyntax, "complex keys (? key, : value) are not supported")
+		p.pos++
+		return NewNull()
+	}
+	if text[0] == '"' || text[0] == '\'' {
+		str, posEnd, isClosed := yaml_quoted_read(p.src, line.posInSrc)
+		if !isClosed {
+			p.errAdd(line.posInSrc, ErrUnclosedString, "")
+			p.pos = len(p.lines)
+		} else {
+			p.line_rest_check(posEnd)
+		}
+		return p.scalar_value(str, false, line.posInSrc)
+	}
+	return p.scalar_value(p.plain_multiline_read(parentIndent), true, line.posInSrc)
+}
+
+// after a flow collection or a quoted scalar, only a comment can be in the line.
+// the actual line is set to the next one
+func (p *yamlParser) line_rest_check(posEnd int) {
+	for p.pos < len(p.lines)-1 && p.lines[p.pos+1].posInSrc-p.lines[p.pos+1].indent <= posEnd {
+		p.pos++ // the value was multi-line
+	}
+	line := p.lines[p.pos]
+	restStart := posEnd - line.posInSrc
+	if restStart < 0 {
+		restStart = 0
+	}
+	if restStart < len(line.text) {
+		rest := line.text[restStart:]
+		if !yaml_line_is_blank(yamlLine{text: rest}) {
+			p.errAdd(posEnd, ErrYamlSyntax, "unexpected content after the value: "+strings.TrimSpace(rest))
+		}
+	}
+	p.pos++
+}
+
+func (p *yamlParser) alias_parse(line yamlLine) JSON_value {
+	name := yaml_word_read(line.text[1:])
+	p.pos++
+	if !yaml_line_is_blank(yamlLine{text: line.text[1+len(name):]}) {
+		p.errAdd(line.posInSrc, ErrYamlSyntax, "unexpected content after the alias")
+	}
+	value, isKnown := p.anchors[name]
+	if !isKnown {
+		p.errAdd(line.posInSrc, ErrYamlUnknownAlias, "unknown alias: *"+name)
+		return NewNull()
+	}
+	return yaml_value_copy(value)
+}
+
+func (p *yamlParser) block_sequence_parse(indent int) JSON_value {
+	seq := NewArr()
+	for {
+		p.blank_lines_skip()
+		if p.pos >= len(p.lines) || p.lines[p.pos].indent < indent {
+			break
+		}
+		line := p.lines[p.pos]
+		if line.indent > indent || strings.HasPrefix(line.text, "\t") {
+			p.errAdd(line.posInSrc, ErrYamlIndentation, "")
+			p.pos++
+			continue
+		}
+		if !(line.text == "-" || strings.HasPrefix(line.text, "- ") || strings.HasPrefix(line.text, "-\t")) {
+			break // a mapping key at the same level, for example
+		}
+
+		offset := 1
+		for offset < len(line.text) && (line.text[offset] == ' ' || line.text[offset] == '\t') {
+			offset++
+		}
+		if yaml_line_is_blank(yamlLine{text: line.text[offset:]}) { // the item is in the next lines
+			p.pos++
+		} else {
+			p.line_virtual_set(offset, false)
+		}
+		seq.ValArray = append(seq.ValArray, p.block_node_parse(indent))
+	}
+	return seq
+}
+
+func (p *yamlParser) block_mapping_parse(indent int) JSON_value {
+	obj := NewObj()
+	for {
+		p.blank_lines_skip()
+		if p.pos >= len(p.lines) || p.lines[p.pos].indent < indent {
+			break
+		}
+		line := p.lines[p.pos]
+		if line.indent > indent || strings.HasPrefix(line.text, "\t") {
+			p.errAdd(line.posInSrc, ErrYamlIndentation, "")
+			p.pos++
+			continue
+		}
+		key, offset, isEntry := yaml_mapping_entry_split(line.text)
+		if !isEntry {
+			if strings.HasPrefix(line.text, "- ") || line.text == "-" {
+				p.errAdd(line.posInSrc, ErrYamlIndentation, "sequence item in a mapping")
+			} else {
+				p.errAdd(line.posInSrc, ErrYamlSyntax, "mapping entry (key: value) is expected")
+			}
+			p.pos++
+			continue
+		}
+
+		if yaml_line_is_blank(yamlLine{text: line.text[offset:]}) { // the value is in the next lines
+			p.pos++
+			p.blank_lines_skip()
+			if p.pos < len(p.lines) && p.lines[p.pos].indent == indent && (p.lines[p.pos].text == "-" || strings.HasPrefix(p.lines[p.pos].text, "- ")) {
+				obj.valObject_set(key, p.block_sequence_parse(indent)) // key:\n- a\n- b   is accepted
+			} else {
+				obj.valObject_set(key, p.block_node_parse(indent))
+			}
+		} else {
+			p.line_virtual_set(offset, true)
+			obj.valObject_set(key, p.block_node_parse(indent))
+		}
+	}
+	return obj
+}
+
+// key: value -> the key, and the position of the value in the text
+func yaml_mapping_entry_split(text string) (string, int, bool) { // TESTED
+	if text == "" || strings.ContainsRune("?[]{}#&*!|>%@`,", rune(text[0])) {
+		return "", 0, false
+	}
+	if text == "-" || strings.HasPrefix(text, "- ") || strings.HasPrefix(text, "-\t") {
+		return "", 0, false
+	}
+
+	key := ""
+	posAfterKey := 0
+	if text[0] == '"' || text[0] == '\'' {
+		str, posEnd, isClosed := yaml_quoted_read([]byte(text), 0)
+		if !isClosed {
+			return "", 0, false
+		}
+		key, posAfterKey = str, posEnd
+		for posAfterKey < len(text) && text[posAfterKey] == ' ' {
+			posAfterKey++
+		}
+		if posAfterKey >= len(text) || text[posAfterKey] != ':' {
+			return "", 0, false
+		}
+	} else {
+		posAfterKey = -1
+		for pos := 0; pos < len(text); pos++ {
+			if text[pos] == '#' && pos > 0 && (text[pos-1] == ' ' || text[pos-1] == '\t') {
+				break // comment
+			}
+			if text[pos] == ':' && (pos+1 == len(text) || text[pos+1] == ' ' || text[pos+1] == '\t') {
+				posAfterKey = pos
+				break
+			}
+		}
+		if posAfterKey == -1 {
+			return "", 0, false
+		}
+		key = strings.TrimRight(text[:posAfterKey], " \t")
+	}
+
+	posValue := posAfterKey + 1 // after the colon
+	for posValue < len(text) && (text[posValue] == ' ' || text[posValue] == '\t') {
+		posValue++
+	}
+	return key, posValue, true
+}
+
+// &anchor and !tag in any order, at the start of a node. offset: the position of the node after them
+func yaml_properties_read(text string) (string, string, int) { // TESTED
+	anchor, tag := "", ""
+	offset := 0
+	for offset < len(text) && (text[offset] == '&' || text[offset] == '!') {
+		word := yaml_word_read(text[offset+1:])
+		if text[offset] == '&' {
+			anchor = word
+		} else {
+			tag = "!" + word
+		}
+		offset += 1 + len(word)
+		for offset < len(text) && (text[offset] == ' ' || text[offset] == '\t') {
+			offset++
+		}
+	}
+	return anchor, tag, offset
+}
+
+// anchor, alias or tag name: till a whitespace or a flow indicator
+func yaml_word_read(text string) string { // TESTED
+	for pos := 0; pos < len(text); pos++ {
+		if strings.ContainsRune(" \t,[]{}", rune(text[pos])) {
+			return text[:pos]
+		}
+	}
+	return text
+}
+
+// the value of a scalar, with the tag of the node. Only a plain scalar is resolved without tag
+func (p *yamlParser) scalar_value(text string, isPlain bool, posInSrc int) JSON_value {
+	tag := p.tag
+	p.tag = ""
+
+	switch tag {
+	case "!!str":
+		return NewStr(text)
+	case "!!int", "!!float", "!!bool", "!!null":
+		resolved := yaml_plain_scalar_resolve(text)
+		if tag == "!!float" && resolved.ValType == 'I' {
+			return NewNumFloat(float64(resolved.ValNumberInt))
+		}
+		typeWanted := map[string]rune{"!!int": 'I', "!!float": 'F', "!!bool": 'b', "!!null": 'n'}[tag]
+		if resolved.ValType != typeWanted {
+			p.errAdd(posInSrc, ErrYamlSyntax, "the value cannot be converted to "+tag+": "+text)
+		}
+		return resolved
+	}
+	if isPlain { // unknown, local tags are ignored
+		return yaml_plain_scalar_resolve(text)
+	}
+	return NewStr(text)
+}
+
+// a plain scalar can be continued in the more indented lines, the lines are folded.
+// "key: value" cannot be in a plain scalar: a: b: c, or a more indented key after a value
+func (p *yamlParser) plain_multiline_read(parentIndent int) string {
+	line := p.lines[p.pos]
+	p.mapping_in_scalar_check(line)
+	text, hasComment := yaml_comment_remove(line.text)
+	p.pos++
+
+	emptyLines := 0
+	for !hasComment && p.pos < len(p.lines) {
+		lineNext := p.lines[p.pos]
+		textNext := strings.TrimSpace(lineNext.text)
+		if textNext == "" {
+			emptyLines++
+			p.pos++
+			continue
+		}
+		if lineNext.indent <= parentIndent || textNext[0] == '#' {
+			break
+		}
+		if emptyLines > 0 {
+			text += strings.Repeat("\n", emptyLines)
+		} else {
+			text += " "
+		}
+		emptyLines = 0
+		p.mapping_in_scalar_check(lineNext)
+		textNext, hasComment = yaml_comment_remove(textNext)
+		text += textNext
+		p.pos++
+	}
+	if emptyLines > 0 { // the empty lines after the scalar are not part of it, step back
+		p.pos -= emptyLines
+	}
+	return text
+}
+
+func (p *yamlParser) mapping_in_scalar_check(line yamlLine) {
+	if _, _, isEntry := yaml_mapping_entry_split(line.text); isEntry {
+		p.errAdd(line.posInSrc, ErrYamlSyntax, "mapping values are not allowed here: "+line.text)
+	}
+}
+
+// a # comment is started after a whitespace, in a plain scalar
+func yaml_comment_remove(text string) (string, bool) { // TESTED
+	for pos := 0; pos < len(text); pos++ {
+		if text[pos] == '#' && (pos == 0 || text[pos-1] == ' ' || text[pos-1] == '\t') {
+			return strings.TrimRight(text[:pos], " \t"), true
+		}
+	}
+	return strings.TrimRight(text, " \t"), false
+}
+
+// | literal or > folded block scalar, the indicator is in the actual line
+func (p *yamlParser) block_scalar_parse(parentIndent int) string {
+	line := p.lines[p.pos]
+	header, _ := yaml_comment_remove(line.text)
+	isFolded := header[0] == '>'
+	chomping := byte('c') // clip: one newline at the end
+	indentFromHeader := 0
+	for _, char := range []byte(header[1:]) {
+		if char == '-' || char == '+' {
+			chomping = char
+		} else if char >= '1' && char <= '9' {
+			indentFromHeader = int(char - '0')
+		} else {
+			p.errAdd(line.posInSrc, ErrYamlSyntax, "invalid block scalar header: "+header)
+		}
+	}
+	p.pos++
+
+	// the indentation of the block: from the header, or from the first non-empty line
+	blockIndent := -1
+	if indentFromHeader > 0 {
+		blockIndent = parentIndent + indentFromHeader
+	} else {
+		for pos := p.pos; pos < len(p.lines); pos++ {
+			if strings.TrimSpace(p.lines[pos].text) != "" {
+				blockIndent = p.lines[pos].indent
+				break
+			}
+		}
+	}
+
+	contentLines := []string{}
+	for ; p.pos < len(p.lines); p.pos++ {
+		lineNow := p.lines[p.pos]
+		if strings.TrimSpace(lineNow.text) == "" {
+			contentLines = append(contentLines, "")
+			continue
+		}
+		if lineNow.indent < blockIndent || lineNow.indent <= parentIndent {
+			break
+		}
+		contentLines = append(contentLines, strings.Repeat(" ", lineNow.indent-blockIndent)+lineNow.text)
+	}
+
+	// the empty lines after the content are handled by the chomping
+	numOfContentLines := len(contentLines)
+	for numOfContentLines > 0 && contentLines[numOfContentLines-1] == "" {
+		numOfContentLines--
+	}
+	trailingNewlines := len(contentLines) - numOfContentLines
+	contentLines = contentLines[:numOfContentLines]
+
+	text := ""
+	if isFolded {
+		text = yaml_folded_lines_join(contentLines)
+	} else {
+		text = strings.Join(contentLines, "\n")
+	}
+
+	if len(contentLines) > 0 && chomping != '-' {
+		text += "\n"
+	}
+	if chomping == '+' {
+		text += strings.Repeat("\n", trailingNewlines)
+	}
+	return text
+}
+
+// folding: the lines are joined with a space, an empty line means a newline.
+// the more indented lines are not folded
+func yaml_folded_lines_join(lines []string) string { // TESTED
+	text := ""
+	lineNonEmptyPrev := ""
+	for pos, line := range lines {
+		if line == "" {
+			text += "\n"
+			continue
+		}
+		isMoreIndented := strings.HasPrefix(line, " ") || strings.HasPrefix(lineNonEmptyPrev, " ")
+		if pos > 0 && lines[pos-1] == "" && isMoreIndented {
+			text += "\n" // the line break is kept around the more indented lines
+		} else if pos > 0 && lines[pos-1] != "" {
+			if isMoreIndented {
+				text += "\n"
+			} else {
+				text += " "
+			}
+		}
+		text += line
+		lineNonEmptyPrev = line
+	}
+	return text
+}
+
+// a quoted scalar from the src position of the opener quote, it can be multi-line.
+// returns the value, the position after the closer quote
+func yaml_quoted_read(src []byte, posOpener int) (string, int, bool) { // TESTED
+	quote := src[posOpener]
+	out := []byte{}
+
+	for pos := posOpener + 1; pos < len(src); pos++ {
+		char := src[pos]
+
+		if char == quote {
+			if quote == '\'' && pos+1 < len(src) && src[pos+1] == '\'' { // '' in single quoted
+				out = append(out, '\'')
+				pos++
+				continue
+			}
+			return string(out), pos + 1, true
+		}
+
+		if char == '\r' || char == '\n' { // line folding
+			out = []byte(strings.TrimRight(string(out), " \t"))
+			emptyLines := 0
+			for pos+1 < len(src) && (src[pos+1] == ' ' || src[pos+1] == '\t' || src[pos+1] == '\r' || src[pos+1] == '\n') {
+				pos++
+				if src[pos] == '\n' {
+					emptyLines++
+				}
+			}
+			if char == '\r' && emptyLines > 0 { // \r\n: the \n is not an empty line
+				emptyLines--
+			}
+			if emptyLines > 0 {
+				out = append(out, strings.Repeat("\n", emptyLines)...)
+			} else {
+				out = append(out, ' ')
+			}
+			continue
+		}
+
+		if char == '\\' && quote == '"' && pos+1 < len(src) {
+			escaped, numOfBytes := yaml_escape_interpret(src[pos+1:])
+			out = append(out, escaped...)
+			pos += numOfBytes
+			continue
+		}
+		out = append(out, char)
+	}
+	return string(out), len(src), false
+}
+
+// the escape sequence after a backslash in a double quoted scalar. numOfBytes: the used bytes after the \
+func yaml_escape_interpret(src []byte) ([]byte, int) { // TESTED
+	simple := map[byte]string{'0': "\x00", 'a': "\a", 'b': "\b", 't': "\t", '\t': "\t", 'n': "\n", 'v': "\v", 'f': "\f", 'r': "\r",
+		'e': "\x1b", ' ': " ", '"': "\"", '/': "/", '\\': "\\", 'N': "\u0085", '_': "\u00a0", 'L': "\u2028", 'P': "\u2029"}
+	if replacement, isSimple := simple[src[0]]; isSimple {
+		return []byte(replacement), 1
+	}
+
+	hexLen := map[byte]int{'x': 2, 'u': 4, 'U': 8}[src[0]]
+	if hexLen > 0 && len(src) > hexLen {
+		if code, err := strconv.ParseUint(string(src[1:1+hexLen]), 16, 32); err == nil {
+			return utf8.AppendRune(nil, rune(code)), 1 + hexLen
+		}
+	}
+
+	if src[0] == '\n' || src[0] == '\r' { // escaped line break: the lines are joined without space
+		numOfBytes := 1
+		for numOfBytes < len(src) && (src[numOfBytes] == ' ' || src[numOfBytes] == '\t' || src[numOfBytes] == '\n') {
+			numOfBytes++
+		}
+		return nil, numOfBytes
+	}
+	return []byte{'\\', src[0]}, 1 // unknown escape, it is kept
+}
+
+// core schema resolution of a plain scalar
+func yaml_plain_scalar_resolve(text string) JSON_value { // TESTED
+	switch text {
+	case "", "~", "null", "Null", "NULL":
+		return NewNull()
+	case "true", "True", "TRUE":
+		return NewBool(true)
+	case "false", "False", "FALSE":
+		return NewBool(false)
+	case ".inf", ".Inf", ".INF", "+.inf", "+.Inf", "+.INF":
+		return NewNumFloat(math.Inf(1))
+	case "-.inf", "-.Inf", "-.INF":
+		return NewNumFloat(math.Inf(-1))
+	case ".nan", ".NaN", ".NAN":
+		return NewNumFloat(math.NaN())
+	}
+
+	if strings.HasPrefix(text, "0x") || strings.HasPrefix(text, "0o") {
+		base := 16
+		if text[1] == 'o' {
+			base = 8
+		}
+		if num, err := strconv.ParseInt(text[2:], base, 64); err == nil {
+			return NewNumInt(int(num))
+		}
+		return NewStr(text)
+	}
+
+	// [-+]? digits, with optional fraction and exponent. strconv accepts more (1_000, inf), those are strings here
+	body := strings.TrimLeft(text, "+-")
+	if len(text)-len(body) > 1 || body == "" || strings.Trim(body, "0123456789.eE+-") != "" || !strings.ContainsAny(body[:1], "0123456789.") {
+		return NewStr(text)
+	}
+	if num, err := strconv.Atoi(text); err == nil {
+		return NewNumInt(num)
+	}
+	if num, err := strconv.ParseFloat(text, 64); err == nil {
+		return NewNumFloat(num)
+	}
+	return NewStr(text)
+}
+
+func yaml_value_copy(value JSON_value) JSON_value { // TESTED
+	if value.ValType == '{' {
+		copied := NewObj()
+		for _, key := range value.ValObject_keys_ordered() {
+			copied.valObject_set(key, yaml_value_copy(value.ValObject[key]))
+		}
+		return copied
+	}
+	if value.ValType == '[' {
+		copied := NewArr()
+		for _, child := range value.ValArray {
+			copied.ValArray = append(copied.ValArray, yaml_value_copy(child))
+		}
+		return copied
+	}
+	return value
+}
+
+////////////////////////////////////////////////////////////////////////////////////
+// flow collections: [a, b] {a: 1}, they can be multi-line
+
+func (p *yamlParser) flow_whitespace_skip(pos int) int {
+	for pos < len(p.src) {
+		char := p.src[pos]
+		if char == ' ' || char == '\t' || char == '\n' || char == '\r' {
+			pos++
+		} else if char == '#' && (pos == 0 || p.src[pos-1] == ' ' || p.src[pos-1] == '\t' || p.src[pos-1] == '\n') {
+			for pos < len(p.src) && p.src[pos] != '\n' {
+				pos++
+			}
+		} else {
+			break
+		}
+	}
+	return pos
+}
+
+// returns the value and the position after it
+func (p *yamlParser) flow_node_parse(pos int) (JSON_value, int) {
+	pos = p.flow_whitespace_skip(pos)
+	if pos >= len(p.src) {
+		p.errAdd(pos, ErrUnclosedContainer, "unclosed flow collection")
+		return NewNull(), pos
+	}
+
+	anchor, tag, offset := yaml_properties_read(yaml_src_window(p.src, pos))
+	if offset > 0 {
+		p.tag = tag
+		value, posEnd := p.flow_node_parse(pos + offset)
+		p.tag = ""
+		if anchor != "" {
+			p.anchors[anchor] = value
+		}
+		return value, posEnd
+	}
+
+	switch p.src[pos] {
+	case '[':
+		p.tag = ""
+		return p.flow_sequence_parse(pos)
+	case '{':
+		p.tag = ""
+		return p.flow_mapping_parse(pos)
+	case '"', '\'':
+		str, posEnd, isClosed := yaml_quoted_read(p.src, pos)
+		if !isClosed {
+			p.errAdd(pos, ErrUnclosedString, "")
+		}
+		return p.scalar_value(str, false, pos), posEnd
+	case '*':
+		name := yaml_word_read(yaml_src_window(p.src, pos+1))
+		value, isKnown := p.anchors[name]
+		if !isKnown {
+			p.errAdd(pos, ErrYamlUnknownAlias, "unknown alias: *"+name)
+			return NewNull(), pos + 1 + len(name)
+		}
+		return yaml_value_copy(value), pos + 1 + len(name)
+	}
+	text, posEnd := p.flow_plain_read(pos)
+	return p.scalar_value(text, true, pos), posEnd
+}
+
+// the start of the src from the position: the properties and the alias names are read from here
+func yaml_src_window(src []byte, pos int) string {
+	posEnd := pos + 256
+	if posEnd > len(src) {
+		posEnd = len(src)
+	}
+	return string(src[pos:posEnd])
+}
+
+// a plain scalar in a flow collection: ends at , [ ] { } or at ": "
+func (p *yamlParser) flow_plain_read(pos int) (string, int) {
+	posStart := pos
+	for ; pos < len(p.src); pos++ {
+		char := p.src[pos]
+		if char == ',' || char == '[' || char == ']' || char == '{' || char == '}' {
+			break
+		}
+		if char == ':' && (pos+1 == len(p.src) || strings.ContainsRune(" \t\r\n,[]{}", rune(p.src[pos+1]))) {
+			break
+		}
+		if char == '#' && pos > posStart && strings.ContainsRune(" \t\n", rune(p.src[pos-1])) {
+			break
+		}
+	}
+	lines := strings.Split(string(p.src[posStart:pos]), "\n")
+	for lineId := range lines {
+		lines[lineId] = strings.TrimSpace(lines[lineId])
+	}
+	return strings.Join(lines, " "), pos // multi-line plain scalar: folded with spaces
+}
+
+func (p *yamlParser) flow_sequence_parse(posOpener int) (JSON_value, int) {
+	seq := NewArr()
+	pos := posOpener + 1
+	for {
+		pos = p.flow_whitespace_skip(pos)
+		if pos >= len(p.src) {
+			p.errAdd(posOpener, ErrUnclosedContainer, "unclosed flow sequence")
+			return seq, pos
+		}
+		if p.src[pos] == ']' {
+			return seq, pos + 1
+		}
+
+		posItem := pos
+		item, posEnd := p.flow_node_parse(pos)
+		pos = p.flow_whitespace_skip(posEnd)
+		if pos < len(p.src) && p.src[pos] == ':' { // single pair mapping: [a: 1, b: 2]
+			value, posValueEnd := p.flow_node_parse(pos + 1)
+			pair := NewObj()
+			pair.valObject_set(yaml_key_text(item, p.src[posItem:posEnd]), value)
+			item = pair
+			pos = p.flow_whitespace_skip(posValueEnd)
+		}
+		seq.ValArray = append(seq.ValArray, item)
+
+		if pos < len(p.src) && p.src[pos] == ',' {
+			pos++
+		} else if pos < len(p.src) && p.src[pos] != ']' {
+			p.errAdd(pos, ErrMissingComma, "")
+			return seq, pos
+		}
+	}
+}
+
+func (p *yamlParser) flow_mapping_parse(posOpener int) (JSON_value, int) {
+	obj := NewObj()
+	pos := posOpener + 1
+	for {
+		pos = p.flow_whitespace_skip(pos)
+		if pos >= len(p.src) {
+			p.errAdd(posOpener, ErrUnclosedContainer, "unclosed flow mapping")
+			return obj, pos
+		}
+		if p.src[pos] == '}' {
+			return obj, pos + 1
+		}
+
+		posKey := pos
+		keyValue, posKeyEnd := p.flow_node_parse(pos)
+		key := yaml_key_text(keyValue, p.src[posKey:posKeyEnd])
+		pos = p.flow_whitespace_skip(posKeyEnd)
+
+		value := NewNull() // {a, b: 1}: a is null
+		if pos < len(p.src) && p.src[pos] == ':' {
+			var posValueEnd int
+			value, posValueEnd = p.flow_node_parse(pos + 1)
+			pos = p.flow_whitespace_skip(posValueEnd)
+		}
+		obj.valObject_set(key, value)
+
+		if pos < len(p.src) && p.src[pos] == ',' {
+			pos++
+		} else if pos < len(p.src) && p.src[pos] != '}' {
+			p.errAdd(pos, ErrMissingComma, "")
+			return obj, pos
+		}
+	}
+}
+
+// the keys are strings: a string key is used, other keys are used with their src text
+func yaml_key_text(key JSON_value, textInSrc []byte) string { // TESTED
+	if key.ValType == '"' {
+		return key.ValRunes
+	}
+	return strings.TrimSpace(string(textInSrc))
+}
+
+////////////////////////////////////////////////////////////////////////////////////
+// YAML emitter: block style output of a JSON_value
+
+type YamlOptions struct {
+	Indent          int // num of spaces in one indentation level. 0 means 2
+	LiteralMinLen   int // a longer string is written as a literal | block scalar. 0 means 80, negative: only multi-line strings
+	FlowArrayMaxLen int // an array of scalars is written in flow style [a, b], if it is not longer. 0: block style only
+
+	KeysInsertionOrder bool // the object keys are written in insertion order. The default: sorted keys
+}
+
+// the YAML 1.1 booleans: older parsers read them as bool, so they are quoted
+var yamlBool11Words = map[string]bool{"y": true, "Y": true, "yes": true, "Yes": true, "YES": true, "n": true, "N": true,
+	"no": true, "No": true, "NO": true, "on": true, "On": true, "ON": true, "off": true, "Off": true, "OFF": true}
+
+func (v JSON_value) ReprYaml(opts YamlOptions) string {
+	if opts.Indent < 1 {
+		opts.Indent = 2
+	}
+	if opts.LiteralMinLen == 0 {
+		opts.LiteralMinLen = 80
+	}
+	indent := base__prefixGenerator_for_repr(" ", opts.Indent)
+	if v.yaml_is_block_collection(opts) {
+		return v.repr_yaml_block(opts, "", indent)
+	}
+	return v.repr_yaml_scalar(opts, indent) + "\n"
+}
+
+// a non-empty object or array, that is not written in flow style
+func (v JSON_value) yaml_is_block_collection(opts YamlOptions) bool {
+	if v.ValType == '{' {
+		return len(v.ValObject) > 0
+	}
+	if v.ValType == '[' {
+		_, isFlow := v.repr_yaml_flow_array(opts)
+		return len(v.ValArray) > 0 && !isFlow
+	}
+	return false
+}
+
+// every line of the collection is started with the prefix, the children are indented with the indent
+func (v JSON_value) repr_yaml_block(opts YamlOptions, prefix, indent string) string {
+	out := ""
+	if v.ValType == '{' {
+		for _, childKey := range v.valObject_keys(opts.KeysInsertionOrder) {
+			child := v.ValObject[childKey]
+			out += prefix + yaml_string_repr_inline(childKey, false) + ":"
+			if child.yaml_is_block_collection(opts) {
+				out += "\n" + child.repr_yaml_block(opts, prefix+indent, indent)
+			} else {
+				out += " " + child.repr_yaml_scalar(opts, prefix+indent) + "\n"
+			}
+		}
+		return out
+	}
+
+	prefixItem := prefix + "  " // the content of an item is after "- "
+	for _, child := range v.ValArray {
+		if child.yaml_is_block_collection(opts) { // the first line of the child is moved after the dash
+			out += prefix + "- " + strings.TrimPrefix(child.repr_yaml_block(opts, prefixItem, indent), prefixItem)
+		} else {
+			out += prefix + "- " + child.repr_yaml_scalar(opts, prefixItem+indent) + "\n"
+		}
+	}
+	return out
+}
+
+// scalars, empty collections and flow arrays in one line - or a literal block scalar,
+// where the content lines are started with the prefix
+func (v JSON_value) repr_yaml_scalar(opts YamlOptions, prefixContent string) string {
+	switch v.ValType {
+	case '"':
+		if yaml_string_is_literal_block(v.ValRunes, opts) {
+			return yaml_string_repr_literal(v.ValRunes, prefixContent)
+		}
+		return yaml_string_repr_inline(v.ValRunes, false)
+	case 'F':
+		if v.ValNumberRaw != "" {
+			return v.ValNumberRaw
+		}
+		return yaml_float_repr(v.ValNumberFloat)
+	case '{':
+		return "{}"
+	case '[':
+		flow, _ := v.repr_yaml_flow_array(opts)
+		return flow
+	}
+	return v.Repr()
+}
+
+// [a, b] if the array has only scalars, and it is short enough
+func (v JSON_value) repr_yaml_flow_array(opts YamlOptions) (string, bool) {
+	if len(v.ValArray) == 0 {
+		return "[]", true
+	}
+	if opts.FlowArrayMaxLen < 1 {
+		return "", false
+	}
+	items := []string{}
+	for _, child := range v.ValArray {
+		switch child.ValType {
+		case '{', '[':
+			return "", false
+		case '"':
+			items = append(items, yaml_string_repr_inline(child.ValRunes, true))
+		case 'F':
+			items = append(items, child.repr_yaml_scalar(opts, ""))
+		default:
+			items = append(items, child.Repr())
+		}
+	}
+	flow := "[" + strings.Join(items, ", ") + "]"
+	return flow, len(flow) <= opts.FlowArrayMaxLen
+}
+
+// a float keeps its type when it is read back: 1.0 instead of 1
+func yaml_float_repr(num float64) string { // TESTED
+	if math.IsNaN(num) {
+		return ".nan"
+	}
+	if math.IsInf(num, 1) {
+		return ".inf"
+	}
+	if math.IsInf(num, -1) {
+		return "-.inf"
+	}
+	out := strconv.FormatFloat(num, 'f', -1, 64)
+	if !strings.Contains(out, ".") {
+		out += ".0"
+	}
+	return out
+}
+
+// plain if the string is read back as the same string, double quoted otherwise
+func yaml_string_repr_inline(text string, inFlow bool) string { // TESTED
+	if yaml_string_is_plain_safe(text) && !(inFlow && strings.ContainsAny(text, ",[]{}")) {
+		return text
+	}
+	return strconv.Quote(text) // the Go escapes are valid YAML escapes
+}
+
+func yaml_string_is_plain_safe(text string) bool { // TESTED
+	if text == "" || yamlBool11Words[text] || yaml_plain_scalar_resolve(text).ValType != '"' {
+		return false
+	}
+	if strings.ContainsRune("-?:,[]{}#&*!|>'\"%@`~ \t", rune(text[0])) || strings.HasSuffix(text, ":") || strings.HasSuffix(text, " ") {
+		return false
+	}
+	if strings.Contains(text, ": ") || strings.Contains(text, " #") || strings.HasPrefix(text, "...") {
+		return false
+	}
+	if strings.ContainsAny(text, "0123456789") && strings.Trim(text, "0123456789_:.eE+-xXoObB") == "" { // YAML 1.1 numbers: 0755, 1_000, 1:30
+		return false
+	}
+	for _, char := range text {
+		if char < ' ' || char == 0x7f || !unicode.IsPrint(char) {
+			return false
+		}
+	}
+	return true
+}
+
+// multi-line or long strings, if they can be written back without escaping
+func yaml_string_is_literal_block(text string, opts YamlOptions) bool { // TESTED
+	isMultiLine := strings.Contains(text, "\n")
+	isLong := opts.LiteralMinLen > 0 && utf8.RuneCountInString(text) > opts.LiteralMinLen
+	if !(isMultiLine || isLong) || strings.TrimRight(text, "\n") == "" {
+		return false
+	}
+	if strings.HasPrefix(strings.TrimLeft(text, "\n"), " ") { // the indentation is detected from the first non-empty line
+		return false
+	}
+	for _, line := range strings.Split(text, "\n") {
+		if line != "" && strings.TrimSpace(line) == "" { // whitespace only line would be an empty line
+			return false
+		}
+	}
+	for _, char := range text {
+		if (char < ' ' && char != '\n' && char != '\t') || char == 0x7f || char == '\uFEFF' {
+			return false
+		}
+	}
+	return true
+}
+
+// | block scalar, the chomping indicator keeps the newlines of the end
+func yaml_string_repr_literal(text string, prefixContent string) string { // TESTED
+	content := strings.TrimRight(text, "\n")
+	numOfNewlinesEnd := len(text) - len(content)
+	header := map[bool]string{true: "|+", false: "|"}[numOfNewlinesEnd > 1]
+	if numOfNewlinesEnd == 0 {
+		header = "|-"
+	}
+
+	out := header
+	for _, line := range strings.Split(content, "\n") {
+		if line == "" {
+			out += "\n"
+		} else {
+			out += "\n" + prefixContent + line
+		}
+	}
+	if numOfNewlinesEnd > 1 { // with keep chomping, the empty lines are written
+		out += strings.Repeat("\n", numOfNewlinesEnd-1)
+	}
+	return out
+}
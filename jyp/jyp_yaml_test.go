@@ -0,0 +1,412 @@
+/*
+Copyright (c) 2024, Balazs Nyiro, balazs.nyiro.ca@gmail.com
+All rights reserved.
+
+This source code (all file in this repo) is licensed
+under the Apache-2 style license found in the
+LICENSE file in the root directory of this source tree.
+
+*/
+
+package jyp
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+var srcYamlKubernetes = `# two documents, a deployment and a service
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels: &labels
+    app: web
+    tier: "frontend"
+spec:
+  replicas: 3
+  selector:
+    matchLabels: *labels
+  template:
+    spec:
+      containers:
+      - name: web
+        image: "nginx:1.25"
+        args: ["--port", 8080]
+        env:
+          - name: DEBUG
+            value: 'false'
+        ports:
+        - containerPort: 80   # http
+          protocol: TCP
+---
+apiVersion: v1
+kind: Service
+metadata: {name: web, annotations: {}}
+spec:
+  ports: [{port: 80, targetPort: 8080}]
+`
+
+// go test -v -run Test_YamlParse_kubernetes
+func Test_YamlParse_kubernetes(t *testing.T) {
+	funName := "Test_YamlParse_kubernetes"
+
+	testName := funName + "_documents"
+	docs, errorsCollected := YamlParseAll(srcYamlKubernetes)
+	compare_int_int(testName, 0, len(errorsCollected), t)
+	compare_int_int(testName, 2, len(docs), t)
+
+	testName = funName + "_deployment"
+	deployment := docs[0]
+	compare_str_str(testName, `{"app":"web","tier":"frontend"}`, deployment.ValObject["spec"].ValObject["selector"].ValObject["matchLabels"].Repr(), t)
+	compare_int_int(testName, 3, deployment.ValObject["spec"].ValObject["replicas"].ValNumberInt, t)
+	container, _ := deployment.GetPath("/spec/template/spec/containers")
+	wanted := `[{"args":["--port",8080],"env":[{"name":"DEBUG","value":"false"}],"image":"nginx:1.25","name":"web","ports":[{"containerPort":80,"protocol":"TCP"}]}]`
+	compare_str_str(testName, wanted, container.Repr(), t)
+
+	testName = funName + "_service"
+	compare_str_str(testName, `{"annotations":{},"name":"web"}`, docs[1].ValObject["metadata"].Repr(), t)
+	compare_str_str(testName, `[{"port":80,"targetPort":8080}]`, docs[1].ValObject["spec"].ValObject["ports"].Repr(), t)
+
+	testName = funName + "_first_document"
+	root, errorsCollected := YamlParse(srcYamlKubernetes)
+	compare_int_int(testName, 0, len(errorsCollected), t)
+	compare_str_str(testName, "Deployment", root.ValObject["kind"].ValRunes, t)
+}
+
+// go test -v -run Test_YamlParse_scalars
+func Test_YamlParse_scalars(t *testing.T) {
+	funName := "Test_YamlParse_scalars"
+
+	testName := funName + "_styles"
+	src := `plain: some text
+  continued here
+single: 'it''s'
+double: "tab\there \u00e9 \x41"
+multiline: "first
+  second
+
+  third"
+literal: |
+  line 1
+    indented
+
+  line 3
+folded: >
+  folded
+  text
+
+  new paragraph
+strip: |-
+  no newline
+keep: |+
+  kept
+
+
+last: end`
+	root, errorsCollected := YamlParse(src)
+	compare_int_int(testName, 0, len(errorsCollected), t)
+	compare_str_str(testName, "some text continued here", root.ValObject["plain"].ValRunes, t)
+	compare_str_str(testName, "it's", root.ValObject["single"].ValRunes, t)
+	compare_str_str(testName, "tab\there é A", root.ValObject["double"].ValRunes, t)
+	compare_str_str(testName, "first second\nthird", root.ValObject["multiline"].ValRunes, t)
+	compare_str_str(testName, "line 1\n  indented\n\nline 3\n", root.ValObject["literal"].ValRunes, t)
+	compare_str_str(testName, "folded text\nnew paragraph\n", root.ValObject["folded"].ValRunes, t)
+	compare_str_str(testName, "no newline", root.ValObject["strip"].ValRunes, t)
+	compare_str_str(testName, "kept\n\n\n", root.ValObject["keep"].ValRunes, t)
+	compare_str_str(testName, "end", root.ValObject["last"].ValRunes, t)
+
+	testName = funName + "_core_schema"
+	root, errorsCollected = YamlParse("[~, null, true, False, 42, -7, 0x1f, 0o17, 1.5, 1e3, .inf, 2024-01-02, '42', 1_000]")
+	compare_int_int(testName, 0, len(errorsCollected), t)
+	compare_str_str(testName, `[null,null,true,false,42,-7,31,15,1.5,1000,null,"2024-01-02","42","1_000"]`, root.Repr(), t)
+	compare_bool_bool(testName, true, math.IsInf(root.ValArray[10].ValNumberFloat, 1), t) // .inf is kept, Repr() writes it as null
+
+	testName = funName + "_tags"
+	root, errorsCollected = YamlParse("a: !!str 42\nb: !!float 1\nc: !!int \"7\"\nd: !custom text\n")
+	compare_int_int(testName, 0, len(errorsCollected), t)
+	compare_str_str(testName, `{"a":"42","b":1,"c":7,"d":"text"}`, root.Repr(), t)
+	compare_rune_rune(testName, 'F', root.ValObject["b"].ValType, t)
+
+	testName = funName + "_tag_conversion_error"
+	_, errorsCollected = YamlParse("a: !!int text\n")
+	compare_int_int(testName, 1, len(errorsCollected), t)
+	compare_bool_bool(testName, true, errors.Is(errorsCollected[0], ErrYamlSyntax), t)
+}
+
+// go test -v -run Test_YamlParse_collections
+func Test_YamlParse_collections(t *testing.T) {
+	funName := "Test_YamlParse_collections"
+
+	testName := funName + "_nested_sequences"
+	root, errorsCollected := YamlParse("- - a\n  - b\n- \n  - c\n-\n- [d, [e]]\n")
+	compare_int_int(testName, 0, len(errorsCollected), t)
+	compare_str_str(testName, `[["a","b"],["c"],null,["d",["e"]]]`, root.Repr(), t)
+
+	testName = funName + "_flow_multiline"
+	root, errorsCollected = YamlParse("{a: [1,\n  2], \"b\": {c: d}, e, 'f': g h, [x: 1]: y}")
+	compare_int_int(testName, 0, len(errorsCollected), t)
+	compare_str_str(testName, `{"[x: 1]":"y","a":[1,2],"b":{"c":"d"},"e":null,"f":"g h"}`, root.Repr(), t)
+
+	testName = funName + "_anchor_copy"
+	root, errorsCollected = YamlParse("base: &b {x: 1}\nother: *b\n")
+	compare_int_int(testName, 0, len(errorsCollected), t)
+	root.ValObject["other"].ValObject["x"] = NewNumInt(2)
+	compare_str_str(testName, `{"base":{"x":1},"other":{"x":2}}`, root.Repr(), t)
+
+	testName = funName + "_keys"
+	root, errorsCollected = YamlParse("1: one\n\"quoted key\": q\nurl: http://example.org\ntime: 12:30\n")
+	compare_int_int(testName, 0, len(errorsCollected), t)
+	compare_str_str(testName, `{"1":"one","quoted key":"q","time":"12:30","url":"http://example.org"}`, root.Repr(), t)
+}
+
+// go test -v -run Test_YamlParse_documents
+func Test_YamlParse_documents(t *testing.T) {
+	funName := "Test_YamlParse_documents"
+
+	testName := funName + "_markers"
+	docs, errorsCollected := YamlParseAll("%YAML 1.2\n---\na: 1\n...\n--- text\n---\n# only comment\n")
+	compare_int_int(testName, 0, len(errorsCollected), t)
+	compare_int_int(testName, 3, len(docs), t)
+	compare_str_str(testName, `{"a":1}`, docs[0].Repr(), t)
+	compare_str_str(testName, `"text"`, docs[1].Repr(), t)
+	compare_str_str(testName, `null`, docs[2].Repr(), t)
+
+	testName = funName + "_empty"
+	root, errorsCollected := YamlParse("# nothing\n")
+	compare_int_int(testName, 0, len(errorsCollected), t)
+	compare_rune_rune(testName, 'n', root.ValType, t)
+
+	testName = funName + "_anchors_per_document"
+	_, errorsCollected = YamlParseAll("a: &x 1\n---\nb: *x\n")
+	compare_int_int(testName, 1, len(errorsCollected), t)
+	compare_bool_bool(testName, true, errors.Is(errorsCollected[0], ErrYamlUnknownAlias), t)
+}
+
+// go test -v -run Test_YamlParse_errors
+func Test_YamlParse_errors(t *testing.T) {
+	funName := "Test_YamlParse_errors"
+
+	testName := funName + "_indentation"
+	_, errorsCollected := YamlParse("a:\n  b: 1\n c: 2\n")
+	compare_bool_bool(testName, true, len(errorsCollected) > 0, t)
+	compare_bool_bool(testName, true, errors.Is(errorsCollected[0], ErrYamlIndentation), t)
+	parseErr := errorsCollected[0].(ParseError)
+	compare_int_int(testName, 3, parseErr.Line, t)
+
+	testName = funName + "_unclosed_flow"
+	_, errorsCollected = YamlParse("a: [1, 2\n")
+	compare_bool_bool(testName, true, len(errorsCollected) > 0, t)
+	compare_bool_bool(testName, true, errors.Is(errorsCollected[0], ErrUnclosedContainer), t)
+
+	testName = funName + "_unclosed_string"
+	_, errorsCollected = YamlParse("a: \"text\n")
+	compare_int_int(testName, 1, len(errorsCollected), t)
+	compare_bool_bool(testName, true, errors.Is(errorsCollected[0], ErrUnclosedString), t)
+
+	testName = funName + "_content_after_value"
+	_, errorsCollected = YamlParse("a: [1] x\n")
+	compare_int_int(testName, 1, len(errorsCollected), t)
+	compare_bool_bool(testName, true, errors.Is(errorsCollected[0], ErrYamlSyntax), t)
+
+	testName = funName + "_not_plain_scalars" // not read silently as a string
+	srcLines := map[string]int{"? complex\n: v\n": 1, "a: b: c\n": 1, "a: 1\n a: 2\n": 2, "- y: 1\n    w: 2\n": 2}
+	for src, lineWanted := range srcLines {
+		_, errorsCollected = YamlParse(src)
+		compare_bool_bool(testName+" "+src, true, len(errorsCollected) > 0, t)
+		compare_bool_bool(testName+" "+src, true, errors.Is(errorsCollected[0], ErrYamlSyntax), t)
+		compare_int_int(testName+" "+src, lineWanted, errorsCollected[0].(ParseError).Line, t)
+	}
+
+	testName = funName + "_tab_indentation"
+	_, errorsCollected = YamlParse("a:\n\tb: 1\n")
+	compare_bool_bool(testName, true, len(errorsCollected) > 0, t)
+	compare_bool_bool(testName, true, errors.Is(errorsCollected[0], ErrYamlIndentation), t)
+}
+
+// go test -v -run Test_yaml_helpers
+func Test_yaml_helpers(t *testing.T) {
+	funName := "Test_yaml_helpers"
+
+	testName := funName + "_lines_split"
+	lines := yaml_lines_split([]byte("a:\r\n  b\n\n"))
+	compare_int_int(testName, 3, len(lines), t)
+	compare_str_str(testName, "a:", lines[0].text, t)
+	compare_int_int(testName, 2, lines[1].indent, t)
+	compare_int_int(testName, 6, lines[1].posInSrc, t)
+	compare_bool_bool(testName, true, yaml_line_is_blank(lines[2]), t)
+	compare_bool_bool(testName, true, yaml_line_is_blank(yamlLine{text: "  # comment"}), t)
+
+	testName = funName + "_documents_split"
+	src := []byte("a\n---\n--- b\n...\n")
+	docs := yaml_documents_split(src, yaml_lines_split(src))
+	compare_int_int(testName, 3, len(docs), t)
+	compare_str_str(testName, "b", docs[2][0].text, t)
+	compare_int_int(testName, 10, docs[2][0].posInSrc, t)
+
+	testName = funName + "_mapping_entry_split"
+	key, posValue, isEntry := yaml_mapping_entry_split("key  : value")
+	compare_bool_bool(testName, true, isEntry, t)
+	compare_str_str(testName, "key", key, t)
+	compare_int_int(testName, 7, posValue, t)
+	key, _, _ = yaml_mapping_entry_split("'a: b': c")
+	compare_str_str(testName, "a: b", key, t)
+	_, _, isEntry = yaml_mapping_entry_split("- a: b")
+	compare_bool_bool(testName, false, isEntry, t)
+	_, _, isEntry = yaml_mapping_entry_split("text # a: b")
+	compare_bool_bool(testName, false, isEntry, t)
+
+	testName = funName + "_properties_read"
+	anchor, tag, offset := yaml_properties_read("!!str &name value")
+	compare_str_str(testName, "name", anchor, t)
+	compare_str_str(testName, "!!str", tag, t)
+	compare_int_int(testName, 12, offset, t)
+	compare_str_str(testName, "name", yaml_word_read("name, x"), t)
+
+	testName = funName + "_comment_remove"
+	text, hasComment := yaml_comment_remove("a#b # c")
+	compare_str_str(testName, "a#b", text, t)
+	compare_bool_bool(testName, true, hasComment, t)
+
+	testName = funName + "_folded_lines_join"
+	compare_str_str(testName, "a b\nc\n\n  d\ne", yaml_folded_lines_join([]string{"a", "b", "", "c", "", "  d", "e"}), t)
+
+	testName = funName + "_quoted_read"
+	text, posEnd, isClosed := yaml_quoted_read([]byte(`x: "a\"b" #`), 3)
+	compare_str_str(testName, `a"b`, text, t)
+	compare_int_int(testName, 9, posEnd, t)
+	compare_bool_bool(testName, true, isClosed, t)
+
+	testName = funName + "_escape_interpret"
+	escaped, numOfBytes := yaml_escape_interpret([]byte("U0001F600"))
+	compare_str_str(testName, "😀", string(escaped), t)
+	compare_int_int(testName, 9, numOfBytes, t)
+	escaped, numOfBytes = yaml_escape_interpret([]byte("\n   x"))
+	compare_str_str(testName, "", string(escaped), t)
+	compare_int_int(testName, 4, numOfBytes, t)
+
+	testName = funName + "_plain_scalar_resolve"
+	compare_rune_rune(testName, 'I', yaml_plain_scalar_resolve("+12").ValType, t)
+	compare_rune_rune(testName, '"', yaml_plain_scalar_resolve("1.2.3").ValType, t)
+	compare_rune_rune(testName, '"', yaml_plain_scalar_resolve("yes").ValType, t)
+	compare_bool_bool(testName, true, math.IsNaN(yaml_plain_scalar_resolve(".nan").ValNumberFloat), t)
+
+	testName = funName + "_key_text"
+	compare_str_str(testName, "a b", yaml_key_text(NewStr("a b"), []byte(`"a b"`)), t)
+	compare_str_str(testName, "1.0", yaml_key_text(NewNumFloat(1), []byte(" 1.0 ")), t)
+
+	testName = funName + "_value_copy"
+	original := NewArr(NewObj())
+	copied := yaml_value_copy(original)
+	copied.ValArray[0].ValObject["k"] = NewNull()
+	compare_int_int(testName, 0, len(original.ValArray[0].ValObject), t)
+}
+
+// go test -v -run Test_ReprYaml
+func Test_ReprYaml(t *testing.T) {
+	funName := "Test_ReprYaml"
+
+	testName := funName + "_block"
+	root, _ := JsonParse(`{"name": "web", "on": {"push": {"branches": ["main"]}}, "replicas": 2, "ratio": 1.0,
+		"quoted": ["yes", "1.0", "~", "", "- item", "a: b", "0755", "true"],
+		"script": "echo a\necho b\n", "empty": {}, "none": [], "nested": [[1, 2], {"a": null, "b": [false]}]}`)
+	wanted := `empty: {}
+name: web
+nested:
+  - - 1
+    - 2
+  - a: null
+    b:
+      - false
+none: []
+"on":
+  push:
+    branches:
+      - main
+quoted:
+  - "yes"
+  - "1.0"
+  - "~"
+  - ""
+  - "- item"
+  - "a: b"
+  - "0755"
+  - "true"
+ratio: 1.0
+replicas: 2
+script: |
+  echo a
+  echo b
+`
+	yamlSrc := root.ReprYaml(YamlOptions{})
+	compare_str_str(testName, wanted, yamlSrc, t)
+
+	testName = funName + "_roundtrip"
+	readBack, errorsCollected := YamlParse(yamlSrc)
+	compare_int_int(testName, 0, len(errorsCollected), t)
+	compare_str_str(testName, root.Repr(), readBack.Repr(), t)
+
+	testName = funName + "_flow_arrays_indent"
+	root, _ = JsonParse(`{"args": ["--port", 8080, "a,b"], "long": ["aaaaaaaaaaaa", "bbbbbbbbbbbb"], "objs": [{"k": "v"}]}`)
+	wanted = `args: ["--port", 8080, "a,b"]
+long:
+    - aaaaaaaaaaaa
+    - bbbbbbbbbbbb
+objs:
+    - k: v
+`
+	compare_str_str(testName, wanted, root.ReprYaml(YamlOptions{Indent: 4, FlowArrayMaxLen: 25}), t)
+
+	testName = funName + "_literal"
+	root = NewArr(NewStr("keep\n\n"), NewStr("strip"), NewStr(" leading\nspace"), NewStr("long text"))
+	wanted = `- |+
+    keep
+
+- strip
+- " leading\nspace"
+- |-
+    long text
+`
+	yamlSrc = root.ReprYaml(YamlOptions{LiteralMinLen: 5})
+	compare_str_str(testName, wanted, yamlSrc, t)
+	readBack, errorsCollected = YamlParse(yamlSrc)
+	compare_int_int(testName, 0, len(errorsCollected), t)
+	compare_str_str(testName, root.Repr(), readBack.Repr(), t)
+
+	testName = funName + "_scalar_root"
+	compare_str_str(testName, "-.inf\n", NewNumFloat(math.Inf(-1)).ReprYaml(YamlOptions{}), t)
+	compare_str_str(testName, "text\n", NewStr("text").ReprYaml(YamlOptions{}), t)
+}
+
+// go test -v -run Test_yaml_emitter_helpers
+func Test_yaml_emitter_helpers(t *testing.T) {
+	funName := "Test_yaml_emitter_helpers"
+
+	testName := funName + "_float_repr"
+	compare_str_str(testName, "2.0", yaml_float_repr(2), t)
+	compare_str_str(testName, "0.25", yaml_float_repr(0.25), t)
+	compare_str_str(testName, ".nan", yaml_float_repr(math.NaN()), t)
+
+	testName = funName + "_string_repr_inline"
+	compare_str_str(testName, "plain text", yaml_string_repr_inline("plain text", false), t)
+	compare_str_str(testName, `"a, b"`, yaml_string_repr_inline("a, b", true), t)
+	compare_str_str(testName, `"tab\there"`, yaml_string_repr_inline("tab\there", false), t)
+
+	testName = funName + "_string_is_plain_safe"
+	compare_bool_bool(testName, true, yaml_string_is_plain_safe("http://example.org"), t)
+	compare_bool_bool(testName, false, yaml_string_is_plain_safe("Off"), t)
+	compare_bool_bool(testName, false, yaml_string_is_plain_safe("text # comment"), t)
+	compare_bool_bool(testName, false, yaml_string_is_plain_safe("1:30"), t)
+	compare_bool_bool(testName, false, yaml_string_is_plain_safe("end "), t)
+
+	testName = funName + "_string_is_literal_block"
+	compare_bool_bool(testName, true, yaml_string_is_literal_block("a\nb", YamlOptions{LiteralMinLen: 80}), t)
+	compare_bool_bool(testName, false, yaml_string_is_literal_block("short", YamlOptions{LiteralMinLen: 80}), t)
+	compare_bool_bool(testName, false, yaml_string_is_literal_block("a\n  \nb", YamlOptions{LiteralMinLen: 80}), t)
+	compare_bool_bool(testName, false, yaml_string_is_literal_block("\n\n", YamlOptions{LiteralMinLen: 80}), t)
+	compare_bool_bool(testName, false, yaml_string_is_literal_block("bell\a\n", YamlOptions{LiteralMinLen: 80}), t)
+
+	testName = funName + "_string_repr_literal"
+	compare_str_str(testName, "|\n  a\n\n  b", yaml_string_repr_literal("a\n\nb\n", "  "), t)
+}